@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"j-and-a/internal/models"
+	"j-and-a/internal/services"
+)
+
+const LOCAL_SUB = "local-dev"
+
+const LOCAL_CURSOR_SIGNING_KEY = "local-dev-cursor-signing-key"
+
+const LOCAL_USAGE = `Usage: %s [flags]
+
+Runs as a Lambda function unless -addr is set, in which case the routes are served over HTTP.
+With -backfill, root items missing their first creation time or log work date are filled in from their first version, then it exits.
+
+Environment:
+  CURSOR_SIGNING_KEY               signs pagination cursors, required unless -addr or -backfill is set
+  DYNAMO_DB_TABLE_NAME             DynamoDB table, unused with -memory
+  DYNAMO_DB_INDEX_NAME             ModelType-SK index
+  DYNAMO_DB_PERSON_INDEX_NAME      PersonId-WorkDateKey index
+  DYNAMO_DB_WORK_DATE_INDEX_NAME   ModelType-WorkDateKey index
+  AWS_ENDPOINT_URL_DYNAMODB        DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local
+
+Flags:
+`
+
+func serveLocal(addr string, sub string) error {
+	return http.ListenAndServe(addr, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, If-Match")
+		w.Header().Set("Access-Control-Allow-Methods", "DELETE, GET, OPTIONS, POST, PUT")
+		w.Header().Set("Access-Control-Expose-Headers", "Content-Disposition, ETag, X-Next-Cursor")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		request, err := newAPIGatewayV2HTTPRequest(r, sub)
+		var response *events.APIGatewayV2HTTPResponse
+		if err != nil {
+			response, err = returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, err)
+		} else {
+			response, err = handler(context.Background(), request)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeAPIGatewayV2HTTPResponse(w, response)
+	}))
+}
+
+func newAPIGatewayV2HTTPRequest(r *http.Request, sub string) (events.APIGatewayV2HTTPRequest, error) {
+	requestedAt := time.Now()
+	request := events.APIGatewayV2HTTPRequest{
+		Version:               "2.0",
+		RawPath:               r.URL.Path,
+		RawQueryString:        r.URL.RawQuery,
+		Headers:               make(map[string]string, len(r.Header)),
+		QueryStringParameters: make(map[string]string),
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RequestID: fmt.Sprintf("local-%d", requestedAt.UnixNano()),
+			Time:      requestedAt.Format("02/Jan/2006:15:04:05 -0700"),
+			TimeEpoch: requestedAt.UnixMilli(),
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Protocol:  r.Proto,
+				SourceIP:  r.RemoteAddr,
+				UserAgent: r.UserAgent(),
+			},
+			Authorizer: &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+				JWT: &events.APIGatewayV2HTTPRequestContextAuthorizerJWTDescription{
+					Claims: map[string]string{"sub": sub},
+				},
+			},
+		},
+	}
+
+	for key, values := range r.Header {
+		request.Headers[strings.ToLower(key)] = strings.Join(values, ",")
+	}
+
+	for key, values := range r.URL.Query() {
+		request.QueryStringParameters[key] = strings.Join(values, ",")
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return request, models.NewModelError(models.ErrValidation, "invalid request body")
+	}
+	request.Body = string(body)
+
+	routeKey, pathParameters, err := matchLocalRouteKey(r.Method, r.URL.Path)
+	if err != nil {
+		return request, err
+	}
+	request.RouteKey = routeKey
+	request.RequestContext.RouteKey = routeKey
+	request.PathParameters = pathParameters
+
+	return request, nil
+}
+
+func matchLocalRouteKey(method string, path string) (string, map[string]string, error) {
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	matchedRouteKey := ""
+	matchedLiterals := -1
+	var matchedPathParameters map[string]string
+	allowedMethods := make([]string, 0)
+	for _, route := range services.Routes {
+		routeSegments := strings.Split(strings.Trim(route.Path(), "/"), "/")
+		if len(routeSegments) != len(pathSegments) {
+			continue
+		}
+
+		literals := 0
+		pathParameters := make(map[string]string)
+		for idx, routeSegment := range routeSegments {
+			if strings.HasPrefix(routeSegment, "{") && strings.HasSuffix(routeSegment, "}") {
+				if pathSegments[idx] == "" {
+					pathParameters = nil
+					break
+				}
+				pathParameters[strings.Trim(routeSegment, "{}")] = pathSegments[idx]
+				continue
+			}
+			if routeSegment != pathSegments[idx] {
+				pathParameters = nil
+				break
+			}
+			literals++
+		}
+		if pathParameters == nil {
+			continue
+		}
+
+		if route.Method() != method {
+			if !slices.Contains(allowedMethods, route.Method()) {
+				allowedMethods = append(allowedMethods, route.Method())
+			}
+			continue
+		}
+
+		if literals > matchedLiterals {
+			matchedRouteKey = route.RouteKey
+			matchedLiterals = literals
+			matchedPathParameters = pathParameters
+		}
+	}
+
+	if matchedRouteKey != "" {
+		return matchedRouteKey, matchedPathParameters, nil
+	}
+	if len(allowedMethods) > 0 {
+		return "", nil, &models.ModelError{Kind: models.ErrMethodNotAllowed, Message: "method not allowed", AllowedMethods: allowedMethods}
+	}
+	return "", nil, models.NewModelError(models.ErrNotFound, "route not found")
+}
+
+func writeAPIGatewayV2HTTPResponse(w http.ResponseWriter, response *events.APIGatewayV2HTTPResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		decodedBody, err := base64.StdEncoding.DecodeString(response.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body = decodedBody
+	}
+
+	w.WriteHeader(response.StatusCode)
+	w.Write(body)
+}
@@ -5,10 +5,14 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -20,116 +24,448 @@ import (
 	"j-and-a/internal/services"
 )
 
-type APIGatewayV2HTTPErrorResponse struct {
-	Name    string `json:"name"`
-	Message string `json:"message"`
+const MAX_LIMIT = 1000
+
+const MAX_CSV_ROWS = 10000
+
+const PROBLEM_TYPE_PREFIX = "urn:j-and-a:problem:"
+
+const (
+	FORMAT_CSV  = "csv"
+	FORMAT_JSON = "json"
+)
+
+type APIGatewayV2HTTPProblemResponse struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail"`
+	Instance string              `json:"instance"`
+	Errors   []models.FieldError `json:"errors,omitempty"`
 }
 
-func returnAPIGatewayV2HTTPErrorResponse(err error) (*events.APIGatewayV2HTTPResponse, error) {
+func returnAPIGatewayV2HTTPProblemResponse(requestId string, err error) (*events.APIGatewayV2HTTPResponse, error) {
 	log.Println(err)
 
-	originalMessage := err.Error()
+	statusCode := http.StatusInternalServerError
+	problemType := "internal"
+	originalMessage := "something went wrong"
+	var fieldErrors []models.FieldError
+	var modelError *models.ModelError
+	if errors.As(err, &modelError) {
+		switch modelError.Kind {
+		case models.ErrConflict:
+			statusCode, problemType = http.StatusConflict, "conflict"
+		case models.ErrForbidden:
+			statusCode, problemType = http.StatusForbidden, "forbidden"
+		case models.ErrMethodNotAllowed:
+			statusCode, problemType = http.StatusMethodNotAllowed, "method-not-allowed"
+		case models.ErrNotFound:
+			statusCode, problemType = http.StatusNotFound, "not-found"
+		case models.ErrPreconditionFailed:
+			statusCode, problemType = http.StatusPreconditionFailed, "precondition-failed"
+		case models.ErrValidation:
+			statusCode, problemType = http.StatusUnprocessableEntity, "validation"
+		}
+		if statusCode != http.StatusInternalServerError {
+			originalMessage = modelError.Message
+			fieldErrors = modelError.FieldErrors
+		}
+	}
 	if len(originalMessage) < 2 {
 		originalMessage = "something went wrong"
 	}
 	message := strings.ToUpper(originalMessage[:1]) + originalMessage[1:]
 
-	bodyBytes, err := json.Marshal(&APIGatewayV2HTTPErrorResponse{Name: "Error", Message: message})
+	bodyBytes, err := json.Marshal(&APIGatewayV2HTTPProblemResponse{
+		Type:     PROBLEM_TYPE_PREFIX + problemType,
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Detail:   message,
+		Instance: requestId,
+		Errors:   fieldErrors,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	headers := map[string]string{"Content-Type": "application/problem+json"}
+	if statusCode == http.StatusMethodNotAllowed {
+		headers["Allow"] = strings.Join(modelError.AllowedMethods, ", ")
+	}
+
 	return &events.APIGatewayV2HTTPResponse{
-		StatusCode: http.StatusBadRequest,
+		StatusCode: statusCode,
+		Headers:    headers,
 		Body:       string(bodyBytes),
 	}, nil
 }
 
-var (
-	client    *dynamodb.Client
-	tableName string
-	indexName string
-)
+func returnAPIGatewayV2HTTPResponse(operation services.Operation, data interface{}) (*events.APIGatewayV2HTTPResponse, error) {
+	if data != nil {
+		bodyBytes, err := json.Marshal(data)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		headers := map[string]string{}
+		switch operation {
+		case services.OperationGetByPartitionId, services.OperationGetByPartitionIdAndSortId:
+			if modelData, ok := data.(models.ModelData); ok {
+				headers["ETag"] = fmt.Sprintf(`"%d"`, modelData.Audit().Version)
+			}
+		}
+
+		return &events.APIGatewayV2HTTPResponse{
+			StatusCode: http.StatusOK,
+			Headers:    headers,
+			Body:       string(bodyBytes),
+		}, nil
+	} else {
+		return &events.APIGatewayV2HTTPResponse{
+			StatusCode: http.StatusOK,
+		}, nil
+	}
+}
+
+func returnAPIGatewayV2HTTPCSVResponse(requestId string, sortType models.ModelType, data interface{}) (*events.APIGatewayV2HTTPResponse, error) {
+	model, err := services.FindModel(sortType)
+	if err != nil {
+		return returnAPIGatewayV2HTTPProblemResponse(requestId, err)
+	}
+	modelData := model.NewModelData()
 
-func init() {
+	headers := map[string]string{
+		"Content-Type":        models.CSV_CONTENT_TYPE,
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s.csv"`, sortType),
+	}
+
+	var datas []models.ModelData
+	switch typedData := data.(type) {
+	case *models.ModelPage:
+		datas = typedData.Items
+		if typedData.NextCursor != "" {
+			headers["X-Next-Cursor"] = typedData.NextCursor
+		}
+	case models.ModelData:
+		datas = []models.ModelData{typedData}
+	}
+
+	body, err := models.EncodeCSV(modelData, datas)
+	if err != nil {
+		return returnAPIGatewayV2HTTPProblemResponse(requestId, err)
+	}
+
+	return &events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Headers:    headers,
+		Body:       body,
+	}, nil
+}
+
+func getPages(modelQuery *models.ModelQuery, allPages bool, getPage func(modelQuery *models.ModelQuery) (interface{}, error)) (interface{}, error) {
+	data, err := getPage(modelQuery)
+	if err != nil || !allPages || modelQuery.Limit > 0 {
+		return data, err
+	}
+
+	modelPage, ok := data.(*models.ModelPage)
+	if !ok {
+		return data, nil
+	}
+
+	items := make([]models.ModelData, 0, len(modelPage.Items))
+	pageModelQuery := *modelQuery
+	for {
+		items = append(items, modelPage.Items...)
+		if len(items) > MAX_CSV_ROWS {
+			return nil, models.NewModelError(models.ErrValidation, fmt.Sprintf("csv export is limited to %d rows, narrow the filters or page with limit and cursor", MAX_CSV_ROWS))
+		}
+		if modelPage.NextCursor == "" {
+			break
+		}
+
+		pageModelQuery.Cursor = modelPage.NextCursor
+		data, err = getPage(&pageModelQuery)
+		if err != nil {
+			return nil, err
+		}
+		modelPage, ok = data.(*models.ModelPage)
+		if !ok {
+			return nil, models.NewModelError(models.ErrInternal, "failed to page through non page data")
+		}
+	}
+
+	return &models.ModelPage{Items: items}, nil
+}
+
+func newResponseFormat(queryStringParameters map[string]string, headers map[string]string) (string, error) {
+	switch queryStringParameters["format"] {
+	case FORMAT_CSV:
+		return FORMAT_CSV, nil
+	case FORMAT_JSON:
+		return FORMAT_JSON, nil
+	case "":
+		if strings.Contains(headers["accept"], "text/csv") {
+			return FORMAT_CSV, nil
+		}
+		return FORMAT_JSON, nil
+	default:
+		return "", models.NewModelError(models.ErrValidation, "invalid format")
+	}
+}
+
+func newModelQuery(queryStringParameters map[string]string) (*models.ModelQuery, error) {
+	var err error
+	modelQuery := &models.ModelQuery{Filters: map[string]string{}}
+	for key, value := range queryStringParameters {
+		switch key {
+		case "asOf":
+			modelQuery.AsOf, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, models.NewModelError(models.ErrValidation, "invalid as of")
+			}
+		case "createdAfter":
+			modelQuery.CreatedAfter, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, models.NewModelError(models.ErrValidation, "invalid created after")
+			}
+		case "createdBefore":
+			modelQuery.CreatedBefore, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, models.NewModelError(models.ErrValidation, "invalid created before")
+			}
+		case "cursor":
+			modelQuery.Cursor = value
+		case "deleted":
+			if value != models.DELETED_FILTER_TRUE && value != models.DELETED_FILTER_FALSE && value != models.DELETED_FILTER_ONLY {
+				return nil, models.NewModelError(models.ErrValidation, "invalid deleted")
+			}
+			modelQuery.Deleted = value
+		case "format", "from", "to":
+		case "limit":
+			modelQuery.Limit, err = strconv.Atoi(value)
+			if err != nil || modelQuery.Limit < 1 || modelQuery.Limit > MAX_LIMIT {
+				return nil, models.NewModelError(models.ErrValidation, "invalid limit")
+			}
+		case "sort":
+			modelQuery.Sort, err = models.ParseSort(value)
+			if err != nil {
+				return nil, err
+			}
+		case "workDateFrom":
+			modelQuery.WorkDateFrom, err = time.Parse(time.DateOnly, value)
+			if err != nil {
+				return nil, models.NewModelError(models.ErrValidation, "invalid work date from")
+			}
+		case "workDateTo":
+			modelQuery.WorkDateTo, err = time.Parse(time.DateOnly, value)
+			if err != nil {
+				return nil, models.NewModelError(models.ErrValidation, "invalid work date to")
+			}
+		default:
+			modelQuery.Filters[key] = value
+		}
+	}
+	if !modelQuery.WorkDateFrom.IsZero() && !modelQuery.WorkDateTo.IsZero() && modelQuery.WorkDateTo.Before(modelQuery.WorkDateFrom) {
+		return nil, models.NewModelError(models.ErrValidation, "invalid work date range")
+	}
+	return modelQuery, nil
+}
+
+type operationHandler func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error)
+
+var operationHandlers = map[services.Operation]operationHandler{
+	services.OperationDeleteByPartitionIdAndSortId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return nil, service.DeleteByPartitionIdAndSortId(ctx)
+	},
+	services.OperationGetByPartitionId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return getPages(modelQuery, format == FORMAT_CSV, func(modelQuery *models.ModelQuery) (interface{}, error) {
+			return service.GetByPartitionId(ctx, modelQuery)
+		})
+	},
+	services.OperationGetByPartitionIdAndSortId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return service.GetByPartitionIdAndSortId(ctx)
+	},
+	services.OperationGetBySortType: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return getPages(modelQuery, format == FORMAT_CSV, func(modelQuery *models.ModelQuery) (interface{}, error) {
+			return service.GetBySortType(ctx, modelQuery)
+		})
+	},
+	services.OperationGetDiffByPartitionIdAndSortId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		fromVersion, fromErr := strconv.Atoi(request.QueryStringParameters["from"])
+		toVersion, toErr := strconv.Atoi(request.QueryStringParameters["to"])
+		if fromErr != nil || toErr != nil {
+			return nil, models.NewModelError(models.ErrValidation, "invalid version")
+		}
+		return service.GetDiffByPartitionIdAndSortId(ctx, fromVersion, toVersion)
+	},
+	services.OperationGetSummaryByPartitionId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return service.GetSummaryByPartitionId(ctx, modelQuery)
+	},
+	services.OperationGetVersionByPartitionIdAndSortId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return service.GetVersionByPartitionIdAndSortId(ctx)
+	},
+	services.OperationGetVersionsByPartitionIdAndSortId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return service.GetVersionsByPartitionIdAndSortId(ctx)
+	},
+	services.OperationPutByPartitionIdAndSortId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return nil, service.PutByPartitionIdAndSortId(ctx, request.Body)
+	},
+	services.OperationRestoreByPartitionIdAndSortId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return nil, service.RestoreByPartitionIdAndSortId(ctx)
+	},
+	services.OperationRevertByPartitionIdAndSortId: func(ctx context.Context, service services.Service, request events.APIGatewayV2HTTPRequest, modelQuery *models.ModelQuery, format string) (interface{}, error) {
+		return nil, service.RevertByPartitionIdAndSortId(ctx)
+	},
+}
+
+var table repositories.Table
+
+func newDynamoDBTable(cursorSigningKey []byte) (*repositories.DynamoDBTable, error) {
 	cfg, err := config.LoadDefaultConfig(context.Background())
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	client = dynamodb.NewFromConfig(cfg)
-	tableName = os.Getenv("DYNAMO_DB_TABLE_NAME")
-	indexName = os.Getenv("DYNAMO_DB_INDEX_NAME")
+	return &repositories.DynamoDBTable{
+		Client:            dynamodb.NewFromConfig(cfg),
+		TableName:         os.Getenv("DYNAMO_DB_TABLE_NAME"),
+		IndexName:         os.Getenv("DYNAMO_DB_INDEX_NAME"),
+		PersonIndexName:   os.Getenv("DYNAMO_DB_PERSON_INDEX_NAME"),
+		WorkDateIndexName: os.Getenv("DYNAMO_DB_WORK_DATE_INDEX_NAME"),
+		CursorSigningKey:  cursorSigningKey,
+	}, nil
 }
 
 func handler(ctx context.Context, request events.APIGatewayV2HTTPRequest) (*events.APIGatewayV2HTTPResponse, error) {
 	if request.IsBase64Encoded {
 		decodedRequestBody, err := base64.StdEncoding.DecodeString(request.Body)
 		if err != nil {
-			return returnAPIGatewayV2HTTPErrorResponse(err)
+			return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, models.NewModelError(models.ErrValidation, "invalid request body"))
 		}
 		request.Body = string(decodedRequestBody)
 	}
 
 	jsonRequest, err := json.Marshal(request)
 	if err != nil {
-		return returnAPIGatewayV2HTTPErrorResponse(err)
+		return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, err)
 	}
 	log.Printf("request %s", string(jsonRequest))
 
 	ctx = context.WithValue(ctx, "requestedAt", request.RequestContext.TimeEpoch)
 	ctx = context.WithValue(ctx, "requestedBy", request.RequestContext.Authorizer.JWT.Claims["sub"])
 
-	repository := &repositories.Repository{Client: client, TableName: tableName, IndexName: indexName}
+	if ifMatchHeader, ok := request.Headers["if-match"]; ok && ifMatchHeader != "*" {
+		expectedVersion, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatchHeader, "W/"), `"`))
+		if err != nil {
+			return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, models.NewModelError(models.ErrValidation, "invalid if match"))
+		}
+		ctx = context.WithValue(ctx, "expectedVersion", expectedVersion)
+	}
+
+	route, err := services.FindRoute(request.RouteKey)
+	if err != nil {
+		return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, err)
+	}
+
+	if route.Operation == services.OperationGetTimesheet {
+		timesheet, err := services.NewReportService(table).GetTimesheet(ctx, request.QueryStringParameters["weekOf"])
+		if err != nil {
+			return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, err)
+		}
+		return returnAPIGatewayV2HTTPResponse(route.Operation, timesheet)
+	}
+
+	version := 0
+	if versionPathParameter, ok := request.PathParameters["Version"]; ok {
+		version, err = strconv.Atoi(versionPathParameter)
+		if err != nil {
+			return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, models.NewModelError(models.ErrValidation, "invalid version"))
+		}
+	}
 
 	modelIdentifiers := &models.ModelIdentifiers{
 		PartitionType: models.ModelType(request.PathParameters["PartitionType"]),
 		PartitionId:   request.PathParameters["PartitionId"],
 		SortType:      models.ModelType(request.PathParameters["SortType"]),
 		SortId:        request.PathParameters["SortId"],
+		Version:       version,
 	}
 
-	service, err := services.New(repository, modelIdentifiers, request.RouteKey)
+	modelQuery, err := newModelQuery(request.QueryStringParameters)
 	if err != nil {
-		return returnAPIGatewayV2HTTPErrorResponse(err)
-	}
-
-	var data interface{}
-	switch request.RouteKey {
-	case "DELETE /{PartitionType}/{PartitionId}/{SortType}", "DELETE /{PartitionType}/{PartitionId}/{SortType}/{SortId}":
-		err = service.DeleteByPartitionIdAndSortId(ctx)
-	case "GET /{PartitionType}/{PartitionId}/{SortType}":
-		data, err = service.GetByPartitionId(ctx)
-	case "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}":
-		data, err = service.GetByPartitionIdAndSortId(ctx)
-	case "GET /{SortType}":
-		data, err = service.GetBySortType(ctx)
-	case "PUT /{PartitionType}/{PartitionId}/{SortType}", "PUT /{PartitionType}/{PartitionId}/{SortType}/{SortId}":
-		err = service.PutByPartitionIdAndSortId(ctx, request.Body)
-	default:
-		err = errors.New("unsupported service action")
+		return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, err)
 	}
 
+	format, err := newResponseFormat(request.QueryStringParameters, request.Headers)
 	if err != nil {
-		return returnAPIGatewayV2HTTPErrorResponse(err)
+		return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, err)
 	}
 
-	if data != nil {
-		bodyBytes, err := json.Marshal(data)
-		if err != nil {
-			log.Fatal(err)
+	service, err := services.New(table, modelIdentifiers, route)
+	if err != nil {
+		return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, err)
+	}
+
+	data, err := operationHandlers[route.Operation](ctx, service, request, modelQuery, format)
+	if err != nil {
+		return returnAPIGatewayV2HTTPProblemResponse(request.RequestContext.RequestID, err)
+	}
+
+	switch route.Operation {
+	case services.OperationGetByPartitionId, services.OperationGetBySortType:
+		if format == FORMAT_CSV {
+			return returnAPIGatewayV2HTTPCSVResponse(request.RequestContext.RequestID, modelIdentifiers.SortType, data)
 		}
-		return &events.APIGatewayV2HTTPResponse{
-			StatusCode: http.StatusOK,
-			Body:       string(bodyBytes),
-		}, nil
-	} else {
-		return &events.APIGatewayV2HTTPResponse{
-			StatusCode: http.StatusOK,
-		}, nil
 	}
+
+	return returnAPIGatewayV2HTTPResponse(route.Operation, data)
 }
 
 func main() {
-	lambda.Start(handler)
+	addr := flag.String("addr", "", "serve routes over HTTP on this address instead of running as a Lambda function")
+	sub := flag.String("sub", LOCAL_SUB, "JWT sub claim attached to local requests")
+	memory := flag.Bool("memory", false, "use an in-memory repository for local requests")
+	backfill := flag.Bool("backfill", false, "backfill the first creation time and log work date of root items in DynamoDB and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), LOCAL_USAGE, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cursorSigningKey := []byte(os.Getenv("CURSOR_SIGNING_KEY"))
+	if len(cursorSigningKey) == 0 {
+		if *addr == "" && !*backfill {
+			log.Fatal("missing cursor signing key")
+		}
+		cursorSigningKey = []byte(LOCAL_CURSOR_SIGNING_KEY)
+	}
+
+	if *addr != "" && *memory {
+		table = repositories.NewMemoryTable(cursorSigningKey)
+	} else {
+		dynamoDBTable, err := newDynamoDBTable(cursorSigningKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+		table = dynamoDBTable
+	}
+
+	if *backfill {
+		backfilled, err := table.BackfillRootItems(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("backfilled %d root items", backfilled)
+		return
+	}
+
+	if *addr == "" {
+		lambda.Start(handler)
+		return
+	}
+
+	log.Printf("serving local api on %s", *addr)
+	log.Fatal(serveLocal(*addr, *sub))
 }
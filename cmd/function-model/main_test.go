@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"j-and-a/internal/models"
+)
+
+func assertErrorKind(t *testing.T, err error, expectedKind error) {
+	t.Helper()
+	if expectedKind == nil {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return
+	}
+	if !errors.Is(err, expectedKind) {
+		t.Fatalf("expected %v, got %v", expectedKind, err)
+	}
+}
+
+func TestGetPages(t *testing.T) {
+	tests := []struct {
+		name          string
+		modelQuery    *models.ModelQuery
+		allPages      bool
+		pageSizes     []int
+		expectedKind  error
+		expectedItems int
+		expectedPages int
+	}{
+		{name: "single page", modelQuery: new(models.ModelQuery), pageSizes: []int{2, 2}, expectedItems: 2, expectedPages: 1},
+		{name: "all pages", modelQuery: new(models.ModelQuery), allPages: true, pageSizes: []int{2, 2, 1}, expectedItems: 5, expectedPages: 3},
+		{name: "all pages with limit", modelQuery: &models.ModelQuery{Limit: 2}, allPages: true, pageSizes: []int{2, 2}, expectedItems: 2, expectedPages: 1},
+		{name: "at row limit", modelQuery: new(models.ModelQuery), allPages: true, pageSizes: []int{MAX_CSV_ROWS / 2, MAX_CSV_ROWS / 2}, expectedItems: MAX_CSV_ROWS, expectedPages: 2},
+		{name: "over row limit", modelQuery: new(models.ModelQuery), allPages: true, pageSizes: []int{MAX_CSV_ROWS / 2, MAX_CSV_ROWS / 2, 1}, expectedKind: models.ErrValidation, expectedPages: 3},
+		{name: "first page over row limit", modelQuery: new(models.ModelQuery), allPages: true, pageSizes: []int{MAX_CSV_ROWS + 1, 1}, expectedKind: models.ErrValidation, expectedPages: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pages := 0
+			data, err := getPages(test.modelQuery, test.allPages, func(modelQuery *models.ModelQuery) (interface{}, error) {
+				page := 0
+				if modelQuery.Cursor != "" {
+					page, _ = strconv.Atoi(modelQuery.Cursor)
+				}
+				pages++
+
+				modelPage := &models.ModelPage{Items: make([]models.ModelData, test.pageSizes[page])}
+				if page < len(test.pageSizes)-1 {
+					modelPage.NextCursor = strconv.Itoa(page + 1)
+				}
+				return modelPage, nil
+			})
+			assertErrorKind(t, err, test.expectedKind)
+			if pages != test.expectedPages {
+				t.Fatalf("expected %d pages, got %d", test.expectedPages, pages)
+			}
+			if test.expectedKind != nil {
+				return
+			}
+
+			modelPage := data.(*models.ModelPage)
+			if len(modelPage.Items) != test.expectedItems {
+				t.Fatalf("expected %d items, got %d", test.expectedItems, len(modelPage.Items))
+			}
+			if test.allPages && test.modelQuery.Limit == 0 && modelPage.NextCursor != "" {
+				t.Fatalf("expected no cursor after all pages, got %q", modelPage.NextCursor)
+			}
+		})
+	}
+}
+
+func TestMatchLocalRouteKey(t *testing.T) {
+	tests := []struct {
+		name                   string
+		method                 string
+		path                   string
+		expectedRouteKey       string
+		expectedPathParameters map[string]string
+		expectedKind           error
+		expectedAllowedMethods []string
+	}{
+		{name: "partition", method: "GET", path: "/Job/j1/Log", expectedRouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}", expectedPathParameters: map[string]string{"PartitionType": "Job", "PartitionId": "j1", "SortType": "Log"}},
+		{name: "literal over parameter", method: "GET", path: "/Job/j1/Log/summary", expectedRouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/summary", expectedPathParameters: map[string]string{"PartitionType": "Job", "PartitionId": "j1", "SortType": "Log"}},
+		{name: "sort ID", method: "GET", path: "/Job/j1/Log/l1/", expectedRouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}", expectedPathParameters: map[string]string{"PartitionType": "Job", "PartitionId": "j1", "SortType": "Log", "SortId": "l1"}},
+		{name: "restore", method: "POST", path: "/Job/j1/Log/l1/restore", expectedRouteKey: "POST /{PartitionType}/{PartitionId}/{SortType}/{SortId}/restore", expectedPathParameters: map[string]string{"PartitionType": "Job", "PartitionId": "j1", "SortType": "Log", "SortId": "l1"}},
+		{name: "sort type method not allowed", method: "DELETE", path: "/Log", expectedKind: models.ErrMethodNotAllowed, expectedAllowedMethods: []string{"GET"}},
+		{name: "sort ID method not allowed", method: "PATCH", path: "/Job/j1/Log/l1", expectedKind: models.ErrMethodNotAllowed, expectedAllowedMethods: []string{"DELETE", "GET", "PUT"}},
+		{name: "empty path parameter", method: "GET", path: "/Job//Log", expectedKind: models.ErrNotFound},
+		{name: "unknown path", method: "GET", path: "/Job/j1/Log/l1/versions/1/revert/again", expectedKind: models.ErrNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routeKey, pathParameters, err := matchLocalRouteKey(test.method, test.path)
+			assertErrorKind(t, err, test.expectedKind)
+			if routeKey != test.expectedRouteKey {
+				t.Fatalf("expected route key %q, got %q", test.expectedRouteKey, routeKey)
+			}
+			if fmt.Sprint(pathParameters) != fmt.Sprint(test.expectedPathParameters) {
+				t.Fatalf("expected path parameters %v, got %v", test.expectedPathParameters, pathParameters)
+			}
+
+			var modelError *models.ModelError
+			if errors.As(err, &modelError) && fmt.Sprint(modelError.AllowedMethods) != fmt.Sprint(test.expectedAllowedMethods) {
+				t.Fatalf("expected allowed methods %v, got %v", test.expectedAllowedMethods, modelError.AllowedMethods)
+			}
+		})
+	}
+}
+
+func TestNewModelQuery(t *testing.T) {
+	asOf := time.Date(2025, 1, 20, 8, 30, 0, 0, time.UTC)
+	workDateFrom := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	workDateTo := time.Date(2025, 1, 26, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                  string
+		queryStringParameters map[string]string
+		expectedKind          error
+		expectedModelQuery    *models.ModelQuery
+	}{
+		{name: "empty", queryStringParameters: map[string]string{}, expectedModelQuery: &models.ModelQuery{Filters: map[string]string{}}},
+		{
+			name:                  "all parameters",
+			queryStringParameters: map[string]string{"asOf": "2025-01-20T08:30:00Z", "createdAfter": "2025-01-20T08:30:00Z", "createdBefore": "2025-01-20T08:30:00Z", "cursor": "c1", "deleted": "only", "limit": "10", "sort": "-workDate", "workDateFrom": "2025-01-20", "workDateTo": "2025-01-26", "format": "csv", "personId": "p1"},
+			expectedModelQuery:    &models.ModelQuery{AsOf: asOf, CreatedAfter: asOf, CreatedBefore: asOf, Cursor: "c1", Deleted: models.DELETED_FILTER_ONLY, Limit: 10, Sort: []models.SortField{{Field: "workDate", Descending: true}}, WorkDateFrom: workDateFrom, WorkDateTo: workDateTo, Filters: map[string]string{"personId": "p1"}},
+		},
+		{name: "same work date", queryStringParameters: map[string]string{"workDateFrom": "2025-01-20", "workDateTo": "2025-01-20"}, expectedModelQuery: &models.ModelQuery{WorkDateFrom: workDateFrom, WorkDateTo: workDateFrom, Filters: map[string]string{}}},
+		{name: "max limit", queryStringParameters: map[string]string{"limit": strconv.Itoa(MAX_LIMIT)}, expectedModelQuery: &models.ModelQuery{Limit: MAX_LIMIT, Filters: map[string]string{}}},
+		{name: "invalid as of", queryStringParameters: map[string]string{"asOf": "2025-01-20"}, expectedKind: models.ErrValidation},
+		{name: "invalid created after", queryStringParameters: map[string]string{"createdAfter": "yesterday"}, expectedKind: models.ErrValidation},
+		{name: "invalid created before", queryStringParameters: map[string]string{"createdBefore": "tomorrow"}, expectedKind: models.ErrValidation},
+		{name: "invalid deleted", queryStringParameters: map[string]string{"deleted": "yes"}, expectedKind: models.ErrValidation},
+		{name: "zero limit", queryStringParameters: map[string]string{"limit": "0"}, expectedKind: models.ErrValidation},
+		{name: "over max limit", queryStringParameters: map[string]string{"limit": strconv.Itoa(MAX_LIMIT + 1)}, expectedKind: models.ErrValidation},
+		{name: "invalid limit", queryStringParameters: map[string]string{"limit": "ten"}, expectedKind: models.ErrValidation},
+		{name: "invalid sort", queryStringParameters: map[string]string{"sort": "--hours"}, expectedKind: models.ErrValidation},
+		{name: "invalid work date from", queryStringParameters: map[string]string{"workDateFrom": "2025-01-20T00:00:00Z"}, expectedKind: models.ErrValidation},
+		{name: "invalid work date to", queryStringParameters: map[string]string{"workDateTo": "01/26/2025"}, expectedKind: models.ErrValidation},
+		{name: "inverted work date range", queryStringParameters: map[string]string{"workDateFrom": "2025-01-26", "workDateTo": "2025-01-20"}, expectedKind: models.ErrValidation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modelQuery, err := newModelQuery(test.queryStringParameters)
+			assertErrorKind(t, err, test.expectedKind)
+			if !reflect.DeepEqual(modelQuery, test.expectedModelQuery) {
+				t.Fatalf("expected model query %+v, got %+v", test.expectedModelQuery, modelQuery)
+			}
+		})
+	}
+}
+
+func TestReturnAPIGatewayV2HTTPProblemResponse(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatusCode int
+		expectedAllow      string
+		expectedProblem    APIGatewayV2HTTPProblemResponse
+	}{
+		{name: "conflict", err: models.NewModelError(models.ErrConflict, "already exists"), expectedStatusCode: 409, expectedProblem: APIGatewayV2HTTPProblemResponse{Type: PROBLEM_TYPE_PREFIX + "conflict", Title: "Conflict", Status: 409, Detail: "Already exists", Instance: "r1"}},
+		{name: "forbidden", err: models.NewModelError(models.ErrForbidden, "not allowed"), expectedStatusCode: 403, expectedProblem: APIGatewayV2HTTPProblemResponse{Type: PROBLEM_TYPE_PREFIX + "forbidden", Title: "Forbidden", Status: 403, Detail: "Not allowed", Instance: "r1"}},
+		{
+			name:               "method not allowed",
+			err:                &models.ModelError{Kind: models.ErrMethodNotAllowed, Message: "method not allowed", AllowedMethods: []string{"DELETE", "GET", "PUT"}},
+			expectedStatusCode: 405,
+			expectedAllow:      "DELETE, GET, PUT",
+			expectedProblem:    APIGatewayV2HTTPProblemResponse{Type: PROBLEM_TYPE_PREFIX + "method-not-allowed", Title: "Method Not Allowed", Status: 405, Detail: "Method not allowed", Instance: "r1"},
+		},
+		{name: "not found", err: models.NewModelError(models.ErrNotFound, "item not found"), expectedStatusCode: 404, expectedProblem: APIGatewayV2HTTPProblemResponse{Type: PROBLEM_TYPE_PREFIX + "not-found", Title: "Not Found", Status: 404, Detail: "Item not found", Instance: "r1"}},
+		{name: "precondition failed", err: models.NewModelError(models.ErrPreconditionFailed, "version mismatch"), expectedStatusCode: 412, expectedProblem: APIGatewayV2HTTPProblemResponse{Type: PROBLEM_TYPE_PREFIX + "precondition-failed", Title: "Precondition Failed", Status: 412, Detail: "Version mismatch", Instance: "r1"}},
+		{
+			name:               "validation",
+			err:                &models.ModelError{Kind: models.ErrValidation, Message: "invalid log", FieldErrors: []models.FieldError{{Field: "hours", Message: "must be between 0 and 24"}}},
+			expectedStatusCode: 422,
+			expectedProblem:    APIGatewayV2HTTPProblemResponse{Type: PROBLEM_TYPE_PREFIX + "validation", Title: "Unprocessable Entity", Status: 422, Detail: "Invalid log", Instance: "r1", Errors: []models.FieldError{{Field: "hours", Message: "must be between 0 and 24"}}},
+		},
+		{name: "internal model error", err: models.NewModelError(models.ErrInternal, "table unavailable"), expectedStatusCode: 500, expectedProblem: APIGatewayV2HTTPProblemResponse{Type: PROBLEM_TYPE_PREFIX + "internal", Title: "Internal Server Error", Status: 500, Detail: "Something went wrong", Instance: "r1"}},
+		{name: "other error", err: errors.New("connection reset"), expectedStatusCode: 500, expectedProblem: APIGatewayV2HTTPProblemResponse{Type: PROBLEM_TYPE_PREFIX + "internal", Title: "Internal Server Error", Status: 500, Detail: "Something went wrong", Instance: "r1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := returnAPIGatewayV2HTTPProblemResponse("r1", test.err)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != test.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", test.expectedStatusCode, response.StatusCode)
+			}
+			if response.Headers["Content-Type"] != "application/problem+json" {
+				t.Fatalf("expected problem content type, got %q", response.Headers["Content-Type"])
+			}
+			if response.Headers["Allow"] != test.expectedAllow {
+				t.Fatalf("expected allow %q, got %q", test.expectedAllow, response.Headers["Allow"])
+			}
+
+			var problem APIGatewayV2HTTPProblemResponse
+			err = json.Unmarshal([]byte(response.Body), &problem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(problem, test.expectedProblem) {
+				t.Fatalf("expected problem %+v, got %+v", test.expectedProblem, problem)
+			}
+		})
+	}
+}
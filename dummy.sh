@@ -29,12 +29,17 @@ build() {
     for dir in cmd/*; do
     if [ -d $dir ]; then
         echo "Building $(basename $dir)..."
-        GOOS=linux GOARCH=arm64 go build -tags lambda.norpc -o $dir/bootstrap $dir/main.go
+        GOOS=linux GOARCH=arm64 go build -tags lambda.norpc -o $dir/bootstrap ./$dir
         echo "Build complete for $(basename $dir)."
     fi
     done
 }
 
+backfill() {
+    echo -e "${BLUE}Backfilling $1 root items...${NC}"
+    DYNAMO_DB_TABLE_NAME=$(terraform output -raw dynamo_db_table_name) go run ../../cmd/function-model -backfill
+}
+
 deploy() {
     terraform init -input=false
     echo
@@ -102,6 +107,9 @@ environment() {
     cd "$ENVIRONMENTS_DIRECTORY_NAME/$1"
 
     case $2 in
+        backfill)
+            backfill $1
+            ;;
         deploy)
             deploy $1
             ;;
@@ -125,6 +133,7 @@ environment() {
             ;;
         *)
             echo -e "${RED}Unsupported subcommand.${NC}"
+            echo "    $BASH_SOURCE <environment> backfill"
             echo "    $BASH_SOURCE <environment> deploy"
             echo "    $BASH_SOURCE <environment> destroy"
             echo "    $BASH_SOURCE <environment> env"
@@ -9,6 +9,11 @@ terraform {
       source  = "integrations/github"
       version = "~> 6.0"
     }
+
+    random = {
+      source  = "hashicorp/random"
+      version = "~> 3.0"
+    }
   }
 }
 
@@ -90,7 +95,9 @@ module "user_pool" {
 }
 
 locals {
-  dynamodb_index_name = "${var.PROJECT_NAME}-${local.environment}-ModelType-SK-index"
+  dynamodb_index_name           = "${var.PROJECT_NAME}-${local.environment}-ModelType-SK-index"
+  dynamodb_person_index_name    = "${var.PROJECT_NAME}-${local.environment}-PersonId-WorkDateKey-index"
+  dynamodb_work_date_index_name = "${var.PROJECT_NAME}-${local.environment}-ModelType-WorkDateKey-index"
 }
 
 module "dynamodb_table" {
@@ -106,6 +113,18 @@ module "dynamodb_table" {
       hash_key        = "ModelType"
       range_key       = "SK"
       projection_type = "ALL"
+    },
+    {
+      name            = local.dynamodb_person_index_name
+      hash_key        = "PersonId"
+      range_key       = "WorkDateKey"
+      projection_type = "ALL"
+    },
+    {
+      name            = local.dynamodb_work_date_index_name
+      hash_key        = "ModelType"
+      range_key       = "WorkDateKey"
+      projection_type = "ALL"
     }
   ]
 
@@ -122,6 +141,14 @@ module "dynamodb_table" {
       name = "ModelType"
       type = "S"
     },
+    {
+      name = "PersonId"
+      type = "S"
+    },
+    {
+      name = "WorkDateKey"
+      type = "S"
+    },
   ]
 }
 
@@ -151,13 +178,25 @@ module "api_gateway" {
   environment  = local.environment
   project_name = var.PROJECT_NAME
   routes = {
-    "DELETE /{PartitionType}/{PartitionId}/{SortType}"          = module.function_model.lambda_function_arn
-    "DELETE /{PartitionType}/{PartitionId}/{SortType}/{SortId}" = module.function_model.lambda_function_arn
-    "GET /{PartitionType}/{PartitionId}/{SortType}"             = module.function_model.lambda_function_arn
-    "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}"    = module.function_model.lambda_function_arn
-    "GET /{SortType}"                                           = module.function_model.lambda_function_arn
-    "PUT /{PartitionType}/{PartitionId}/{SortType}"             = module.function_model.lambda_function_arn
-    "PUT /{PartitionType}/{PartitionId}/{SortType}/{SortId}"    = module.function_model.lambda_function_arn
+    "DELETE /{PartitionType}/{PartitionId}/{SortType}"                                  = module.function_model.lambda_function_arn
+    "DELETE /{PartitionType}/{PartitionId}/{SortType}/{SortId}"                         = module.function_model.lambda_function_arn
+    "GET /{PartitionType}/{PartitionId}/{SortType}"                                     = module.function_model.lambda_function_arn
+    "GET /{PartitionType}/{PartitionId}/{SortType}/summary"                             = module.function_model.lambda_function_arn
+    "GET /{PartitionType}/{PartitionId}/{SortType}/versions"                            = module.function_model.lambda_function_arn
+    "GET /{PartitionType}/{PartitionId}/{SortType}/versions/diff"                       = module.function_model.lambda_function_arn
+    "GET /{PartitionType}/{PartitionId}/{SortType}/versions/{Version}"                  = module.function_model.lambda_function_arn
+    "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}"                            = module.function_model.lambda_function_arn
+    "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions"                   = module.function_model.lambda_function_arn
+    "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions/diff"              = module.function_model.lambda_function_arn
+    "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions/{Version}"         = module.function_model.lambda_function_arn
+    "GET /{SortType}"                                                                   = module.function_model.lambda_function_arn
+    "GET /reports/timesheet"                                                            = module.function_model.lambda_function_arn
+    "POST /{PartitionType}/{PartitionId}/{SortType}/restore"                            = module.function_model.lambda_function_arn
+    "POST /{PartitionType}/{PartitionId}/{SortType}/versions/{Version}/revert"          = module.function_model.lambda_function_arn
+    "POST /{PartitionType}/{PartitionId}/{SortType}/{SortId}/restore"                   = module.function_model.lambda_function_arn
+    "POST /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions/{Version}/revert" = module.function_model.lambda_function_arn
+    "PUT /{PartitionType}/{PartitionId}/{SortType}"                                     = module.function_model.lambda_function_arn
+    "PUT /{PartitionType}/{PartitionId}/{SortType}/{SortId}"                            = module.function_model.lambda_function_arn
   }
   user_pool_id         = module.user_pool.user_pool_id
   user_pool_client_ids = [module.user_pool.user_pool_client_id]
@@ -175,6 +214,11 @@ module "function_iam_policy" {
   )
 }
 
+resource "random_password" "cursor_signing_key" {
+  length  = 64
+  special = false
+}
+
 module "function_model" {
   source = "terraform-aws-modules/lambda/aws"
 
@@ -200,7 +244,10 @@ module "function_model" {
   policy        = module.function_iam_policy.arn
 
   environment_variables = {
-    DYNAMO_DB_TABLE_NAME = module.dynamodb_table.dynamodb_table_id
-    DYNAMO_DB_INDEX_NAME = local.dynamodb_index_name
+    CURSOR_SIGNING_KEY             = random_password.cursor_signing_key.result
+    DYNAMO_DB_TABLE_NAME           = module.dynamodb_table.dynamodb_table_id
+    DYNAMO_DB_INDEX_NAME           = local.dynamodb_index_name
+    DYNAMO_DB_PERSON_INDEX_NAME    = local.dynamodb_person_index_name
+    DYNAMO_DB_WORK_DATE_INDEX_NAME = local.dynamodb_work_date_index_name
   }
 }
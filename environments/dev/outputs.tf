@@ -13,6 +13,11 @@ output "cloudfront_distribution_url" {
   description = "CloudFront distribution URL"
 }
 
+output "dynamo_db_table_name" {
+  value       = module.dynamodb_table.dynamodb_table_id
+  description = "DynamoDB table name"
+}
+
 output "site_s3_bucket_name" {
   value       = module.cdn.site_s3_bucket_name
   description = "Site S3 bucket name"
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const CSV_CONTENT_TYPE = "text/csv; charset=utf-8"
+
+func EncodeCSV(modelData ModelData, datas []ModelData) (string, error) {
+	modelDataType := reflect.TypeOf(modelData)
+	if modelDataType.Kind() != reflect.Pointer || modelDataType.Elem().Kind() != reflect.Struct {
+		return "", errors.New("model data must be pointer to struct")
+	}
+	modelDataType = modelDataType.Elem()
+
+	fieldIndexes := make([]int, 0, modelDataType.NumField())
+	header := make([]string, 0, modelDataType.NumField())
+	for idx := 0; idx < modelDataType.NumField(); idx++ {
+		structField := modelDataType.Field(idx)
+		name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if !structField.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+		fieldIndexes = append(fieldIndexes, idx)
+		header = append(header, name)
+	}
+
+	buffer := new(bytes.Buffer)
+	writer := csv.NewWriter(buffer)
+	err := writer.Write(header)
+	if err != nil {
+		return "", err
+	}
+
+	for _, data := range datas {
+		dataValue := reflect.ValueOf(data)
+		if dataValue.Type() != reflect.TypeOf(modelData) {
+			return "", errors.New("model data must be pointers to structs of the same type")
+		}
+		dataValue = dataValue.Elem()
+
+		record := make([]string, 0, len(fieldIndexes))
+		for _, idx := range fieldIndexes {
+			record = append(record, csvValue(dataValue.Field(idx)))
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func csvValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		stringValue := value.String()
+		if stringValue != "" && strings.ContainsRune("=+-@", rune(stringValue[0])) {
+			return "'" + stringValue
+		}
+		return stringValue
+	case reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	case reflect.Int:
+		return strconv.FormatInt(value.Int(), 10)
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+)
+
+type csvTestData struct {
+	Name     string  `json:"name"`
+	Hours    float64 `json:"hours,omitempty"`
+	Version  int     `json:"version"`
+	Untagged string
+	Ignored  string `json:"-"`
+	private  string
+}
+
+func (d *csvTestData) Audit() ModelAudit {
+	return ModelAudit{}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	tests := []struct {
+		name          string
+		datas         []ModelData
+		expectedError bool
+		expectedCSV   string
+	}{
+		{name: "header only", datas: []ModelData{}, expectedCSV: "name,hours,version,Untagged\n"},
+		{
+			name:        "values",
+			datas:       []ModelData{&csvTestData{Name: "Deck", Hours: 2.5, Version: 3, Untagged: "a, b", Ignored: "x", private: "y"}, &csvTestData{Hours: 8}},
+			expectedCSV: "name,hours,version,Untagged\nDeck,2.5,3,\"a, b\"\n,8,0,\n",
+		},
+		{
+			name:        "formula prefixes",
+			datas:       []ModelData{&csvTestData{Name: "=SUM(A1)"}, &csvTestData{Name: "+1"}, &csvTestData{Name: "-1"}, &csvTestData{Name: "@cmd"}, &csvTestData{Name: "a=b"}},
+			expectedCSV: "name,hours,version,Untagged\n'=SUM(A1),0,0,\n'+1,0,0,\n'-1,0,0,\n'@cmd,0,0,\na=b,0,0,\n",
+		},
+		{name: "different types", datas: []ModelData{&LogData{}}, expectedError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			csv, err := EncodeCSV(&csvTestData{}, test.datas)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("expected error %t, got %v", test.expectedError, err)
+			}
+			if csv != test.expectedCSV {
+				t.Fatalf("expected csv %q, got %q", test.expectedCSV, csv)
+			}
+		})
+	}
+}
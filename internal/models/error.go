@@ -0,0 +1,37 @@
+package models
+
+import "errors"
+
+var (
+	ErrConflict           = errors.New("conflict")
+	ErrForbidden          = errors.New("forbidden")
+	ErrInternal           = errors.New("internal")
+	ErrMethodNotAllowed   = errors.New("method not allowed")
+	ErrNotFound           = errors.New("not found")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrValidation         = errors.New("validation")
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ModelError struct {
+	Kind           error
+	Message        string
+	FieldErrors    []FieldError
+	AllowedMethods []string
+}
+
+func NewModelError(kind error, message string) error {
+	return &ModelError{Kind: kind, Message: message}
+}
+
+func (e *ModelError) Error() string {
+	return e.Message
+}
+
+func (e *ModelError) Unwrap() error {
+	return e.Kind
+}
@@ -0,0 +1,147 @@
+package models
+
+import "strings"
+
+type JobMetadataPayload struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Client  string `json:"client"`
+	Status  string `json:"status"`
+}
+
+func (p *JobMetadataPayload) Validate() error {
+	v := new(Validator)
+	v.Required("name", p.Name)
+	v.MaxLength("name", p.Name, 200)
+	v.MaxLength("address", p.Address, 200)
+	v.MaxLength("client", p.Client, 200)
+	v.MaxLength("status", p.Status, 50)
+	return v.Error()
+}
+
+func (p *JobMetadataPayload) Item(modelIdentifiers *ModelIdentifiers, version int, latestVersion int, createdAt string, createdBy string) ModelItem {
+	return &JobMetadataItem{
+		Name:          p.Name,
+		Address:       p.Address,
+		Client:        p.Client,
+		Status:        p.Status,
+		PK:            EncodePartitionKey(ModelTypeJob, modelIdentifiers.PartitionId),
+		SK:            EncodeSortKey(version, ModelTypeJobMetadata, modelIdentifiers.SortId),
+		ModelType:     ModelTypeJobMetadata,
+		LatestVersion: latestVersion,
+		CreatedAt:     createdAt,
+		CreatedBy:     createdBy,
+		DeletedAt:     "",
+		DeletedBy:     "",
+		RestoredAt:    "",
+		RestoredBy:    "",
+	}
+}
+
+type JobMetadataItem struct {
+	Name          string
+	Address       string
+	Client        string
+	Status        string
+	PK            string
+	SK            string
+	ModelType     string
+	LatestVersion int `dynamodbav:",omitempty"`
+	CreatedAt     string
+	CreatedBy     string
+	DeletedAt     string `dynamodbav:",omitempty"`
+	DeletedBy     string `dynamodbav:",omitempty"`
+	RestoredAt    string `dynamodbav:",omitempty"`
+	RestoredBy    string `dynamodbav:",omitempty"`
+}
+
+func (i *JobMetadataItem) New() ModelItem {
+	return new(JobMetadataItem)
+}
+
+func (i *JobMetadataItem) Data() (ModelData, error) {
+	return i.TypedData()
+}
+
+func (i *JobMetadataItem) TypedData() (*JobMetadataData, error) {
+	_, partitionId, err := DecodePartitionKey(i.PK)
+	if err != nil {
+		return nil, err
+	}
+
+	version, _, _, err := DecodeSortKey(i.SK)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		version = i.LatestVersion
+	}
+
+	return &JobMetadataData{
+		Name:       i.Name,
+		Address:    i.Address,
+		Client:     i.Client,
+		Status:     i.Status,
+		JobId:      partitionId,
+		Version:    version,
+		CreatedAt:  i.CreatedAt,
+		CreatedBy:  i.CreatedBy,
+		DeletedAt:  i.DeletedAt,
+		DeletedBy:  i.DeletedBy,
+		RestoredAt: i.RestoredAt,
+		RestoredBy: i.RestoredBy,
+	}, nil
+}
+
+func (i *JobMetadataItem) Payload() ModelPayload {
+	return &JobMetadataPayload{
+		Name:    i.Name,
+		Address: i.Address,
+		Client:  i.Client,
+		Status:  i.Status,
+	}
+}
+
+type JobMetadataData struct {
+	Name       string `json:"name"`
+	Address    string `json:"address"`
+	Client     string `json:"client"`
+	Status     string `json:"status"`
+	JobId      string `json:"jobId"`
+	Version    int    `json:"version"`
+	CreatedAt  string `json:"createdAt"`
+	CreatedBy  string `json:"createdBy"`
+	DeletedAt  string `json:"deletedAt"`
+	DeletedBy  string `json:"deletedBy"`
+	RestoredAt string `json:"restoredAt"`
+	RestoredBy string `json:"restoredBy"`
+}
+
+func (d *JobMetadataData) Audit() ModelAudit {
+	return ModelAudit{
+		Version:    d.Version,
+		CreatedAt:  d.CreatedAt,
+		CreatedBy:  d.CreatedBy,
+		DeletedAt:  d.DeletedAt,
+		DeletedBy:  d.DeletedBy,
+		RestoredAt: d.RestoredAt,
+		RestoredBy: d.RestoredBy,
+	}
+}
+
+func (d *JobMetadataData) CompareField(other *JobMetadataData, field string) (int, bool) {
+	switch field {
+	case "name":
+		return strings.Compare(d.Name, other.Name), true
+	case "address":
+		return strings.Compare(d.Address, other.Address), true
+	case "client":
+		return strings.Compare(d.Client, other.Client), true
+	case "status":
+		return strings.Compare(d.Status, other.Status), true
+	case "jobId":
+		return strings.Compare(d.JobId, other.JobId), true
+	default:
+		return d.Audit().CompareField(other.Audit(), field)
+	}
+}
@@ -1,36 +1,113 @@
 package models
 
+import (
+	"cmp"
+	"math"
+	"strings"
+	"time"
+)
+
+const TIME_OF_DAY_LAYOUT = "15:04"
+
+const HOURS_TOLERANCE = 0.01
+
 type LogPayload struct {
-	PersonId string  `json:"personId"`
-	Hours    float64 `json:"hours"`
+	PersonId  string  `json:"personId"`
+	WorkDate  string  `json:"workDate"`
+	StartTime string  `json:"startTime"`
+	EndTime   string  `json:"endTime"`
+	Hours     float64 `json:"hours"`
+}
+
+func (p *LogPayload) Validate() error {
+	v := new(Validator)
+	v.UUID("personId", p.PersonId)
+	v.Date("workDate", p.WorkDate)
+	v.Range("hours", p.Hours, 0, 24)
+
+	if p.StartTime == "" && p.EndTime == "" {
+		v.Check(p.Hours != 0, "hours", "must be greater than 0")
+		return v.Error()
+	}
+
+	v.Check(p.StartTime != "", "startTime", "is required with end time")
+	v.Check(p.EndTime != "", "endTime", "is required with start time")
+	if p.StartTime != "" {
+		v.Time("startTime", p.StartTime)
+	}
+	if p.EndTime != "" {
+		v.Time("endTime", p.EndTime)
+	}
+	if len(v.FieldErrors) > 0 {
+		return v.Error()
+	}
+
+	hours, _ := p.timedHours()
+	v.Check(hours > 0, "endTime", "must be after start time")
+	v.Check(p.Hours == 0 || math.Abs(p.Hours-hours) <= HOURS_TOLERANCE, "hours", "must match start time and end time")
+	return v.Error()
+}
+
+func (p *LogPayload) timedHours() (float64, bool) {
+	startTime, err := time.Parse(TIME_OF_DAY_LAYOUT, p.StartTime)
+	if err != nil {
+		return 0, false
+	}
+	endTime, err := time.Parse(TIME_OF_DAY_LAYOUT, p.EndTime)
+	if err != nil {
+		return 0, false
+	}
+	return endTime.Sub(startTime).Hours(), true
 }
 
 func (p *LogPayload) Item(modelIdentifiers *ModelIdentifiers, version int, latestVersion int, createdAt string, createdBy string) ModelItem {
+	hours := p.Hours
+	if timedHours, ok := p.timedHours(); ok {
+		hours = timedHours
+	}
+
+	workDateKey := ""
+	if version == 0 {
+		workDateKey = EncodeWorkDateKey(p.WorkDate, modelIdentifiers.SortId)
+	}
+
 	return &LogItem{
 		PersonId:      p.PersonId,
-		Hours:         p.Hours,
+		WorkDate:      p.WorkDate,
+		StartTime:     p.StartTime,
+		EndTime:       p.EndTime,
+		Hours:         hours,
 		PK:            EncodePartitionKey(ModelTypeJob, modelIdentifiers.PartitionId),
 		SK:            EncodeSortKey(version, ModelTypeLog, modelIdentifiers.SortId),
 		ModelType:     ModelTypeLog,
+		WorkDateKey:   workDateKey,
 		LatestVersion: latestVersion,
 		CreatedAt:     createdAt,
 		CreatedBy:     createdBy,
 		DeletedAt:     "",
 		DeletedBy:     "",
+		RestoredAt:    "",
+		RestoredBy:    "",
 	}
 }
 
 type LogItem struct {
 	PersonId      string
+	WorkDate      string
+	StartTime     string `dynamodbav:",omitempty"`
+	EndTime       string `dynamodbav:",omitempty"`
 	Hours         float64
 	PK            string
 	SK            string
 	ModelType     string
-	LatestVersion int `dynamodbav:",omitempty"`
+	WorkDateKey   string `dynamodbav:",omitempty"`
+	LatestVersion int    `dynamodbav:",omitempty"`
 	CreatedAt     string
 	CreatedBy     string
 	DeletedAt     string `dynamodbav:",omitempty"`
 	DeletedBy     string `dynamodbav:",omitempty"`
+	RestoredAt    string `dynamodbav:",omitempty"`
+	RestoredBy    string `dynamodbav:",omitempty"`
 }
 
 func (i *LogItem) New() ModelItem {
@@ -38,35 +115,119 @@ func (i *LogItem) New() ModelItem {
 }
 
 func (i *LogItem) Data() (ModelData, error) {
+	return i.TypedData()
+}
+
+func (i *LogItem) TypedData() (*LogData, error) {
 	_, partitionId, err := DecodePartitionKey(i.PK)
 	if err != nil {
 		return nil, err
 	}
 
-	_, _, sortId, err := DecodeSortKey(i.SK)
+	version, _, sortId, err := DecodeSortKey(i.SK)
 	if err != nil {
 		return nil, err
 	}
+	if version == 0 {
+		version = i.LatestVersion
+	}
 
 	return &LogData{
+		PersonId:   i.PersonId,
+		WorkDate:   i.WorkDate,
+		StartTime:  i.StartTime,
+		EndTime:    i.EndTime,
+		Hours:      i.Hours,
+		JobId:      partitionId,
+		LogId:      sortId,
+		Version:    version,
+		CreatedAt:  i.CreatedAt,
+		CreatedBy:  i.CreatedBy,
+		DeletedAt:  i.DeletedAt,
+		DeletedBy:  i.DeletedBy,
+		RestoredAt: i.RestoredAt,
+		RestoredBy: i.RestoredBy,
+	}, nil
+}
+
+func (i *LogItem) Payload() ModelPayload {
+	return &LogPayload{
 		PersonId:  i.PersonId,
+		WorkDate:  i.WorkDate,
+		StartTime: i.StartTime,
+		EndTime:   i.EndTime,
 		Hours:     i.Hours,
-		JobId:     partitionId,
-		LogId:     sortId,
-		CreatedAt: i.CreatedAt,
-		CreatedBy: i.CreatedBy,
-		DeletedAt: i.DeletedAt,
-		DeletedBy: i.DeletedBy,
-	}, nil
+	}
 }
 
 type LogData struct {
-	PersonId  string  `json:"personId"`
-	Hours     float64 `json:"hours"`
-	JobId     string  `json:"jobId"`
-	LogId     string  `json:"logId"`
-	CreatedAt string  `json:"createdAt"`
-	CreatedBy string  `json:"createdBy"`
-	DeletedAt string  `json:"deletedAt"`
-	DeletedBy string  `json:"deletedBy"`
+	PersonId   string  `json:"personId"`
+	WorkDate   string  `json:"workDate"`
+	StartTime  string  `json:"startTime"`
+	EndTime    string  `json:"endTime"`
+	Hours      float64 `json:"hours"`
+	JobId      string  `json:"jobId"`
+	LogId      string  `json:"logId"`
+	Version    int     `json:"version"`
+	CreatedAt  string  `json:"createdAt"`
+	CreatedBy  string  `json:"createdBy"`
+	DeletedAt  string  `json:"deletedAt"`
+	DeletedBy  string  `json:"deletedBy"`
+	RestoredAt string  `json:"restoredAt"`
+	RestoredBy string  `json:"restoredBy"`
+}
+
+func (d *LogData) Audit() ModelAudit {
+	return ModelAudit{
+		Version:    d.Version,
+		CreatedAt:  d.CreatedAt,
+		CreatedBy:  d.CreatedBy,
+		DeletedAt:  d.DeletedAt,
+		DeletedBy:  d.DeletedBy,
+		RestoredAt: d.RestoredAt,
+		RestoredBy: d.RestoredBy,
+	}
+}
+
+func (d *LogData) CompareField(other *LogData, field string) (int, bool) {
+	switch field {
+	case "personId":
+		return strings.Compare(d.PersonId, other.PersonId), true
+	case "workDate":
+		return strings.Compare(d.WorkDate, other.WorkDate), true
+	case "startTime":
+		return strings.Compare(d.StartTime, other.StartTime), true
+	case "endTime":
+		return strings.Compare(d.EndTime, other.EndTime), true
+	case "hours":
+		return cmp.Compare(d.Hours, other.Hours), true
+	case "jobId":
+		return strings.Compare(d.JobId, other.JobId), true
+	case "logId":
+		return strings.Compare(d.LogId, other.LogId), true
+	default:
+		return d.Audit().CompareField(other.Audit(), field)
+	}
+}
+
+type LogSummary struct {
+	TotalHours    float64            `json:"totalHours"`
+	LogCount      int                `json:"logCount"`
+	HoursByPerson map[string]float64 `json:"hoursByPerson"`
+	HoursByJob    map[string]float64 `json:"hoursByJob"`
+}
+
+func SummarizeLogs(logDatas []*LogData) *LogSummary {
+	logSummary := &LogSummary{HoursByPerson: map[string]float64{}, HoursByJob: map[string]float64{}}
+	for _, logData := range logDatas {
+		if logData.DeletedAt != "" {
+			continue
+		}
+
+		logSummary.TotalHours += logData.Hours
+		logSummary.LogCount++
+		logSummary.HoursByPerson[logData.PersonId] += logData.Hours
+		logSummary.HoursByJob[logData.JobId] += logData.Hours
+	}
+	return logSummary
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const TEST_PERSON_ID = "019491b4-4d1f-7df2-be95-62e0e684353f"
+
+func TestLogPayloadValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		payload        LogPayload
+		expectedFields []string
+	}{
+		{name: "hours", payload: LogPayload{PersonId: TEST_PERSON_ID, WorkDate: "2025-01-20", Hours: 2}},
+		{name: "start and end time", payload: LogPayload{PersonId: TEST_PERSON_ID, WorkDate: "2025-01-20", StartTime: "08:00", EndTime: "10:30"}},
+		{name: "matching hours", payload: LogPayload{PersonId: TEST_PERSON_ID, WorkDate: "2025-01-20", StartTime: "08:00", EndTime: "10:30", Hours: 2.5}},
+		{name: "empty", payload: LogPayload{}, expectedFields: []string{"personId", "workDate", "hours"}},
+		{name: "invalid person ID", payload: LogPayload{PersonId: "person", WorkDate: "2025-01-20", Hours: 2}, expectedFields: []string{"personId"}},
+		{name: "invalid work date", payload: LogPayload{PersonId: TEST_PERSON_ID, WorkDate: "01/20/2025", Hours: 2}, expectedFields: []string{"workDate"}},
+		{name: "hours out of range", payload: LogPayload{PersonId: TEST_PERSON_ID, WorkDate: "2025-01-20", Hours: 25}, expectedFields: []string{"hours"}},
+		{name: "start time only", payload: LogPayload{PersonId: TEST_PERSON_ID, WorkDate: "2025-01-20", StartTime: "08:00"}, expectedFields: []string{"endTime"}},
+		{name: "invalid end time", payload: LogPayload{PersonId: TEST_PERSON_ID, WorkDate: "2025-01-20", StartTime: "08:00", EndTime: "8pm"}, expectedFields: []string{"endTime"}},
+		{name: "end before start", payload: LogPayload{PersonId: TEST_PERSON_ID, WorkDate: "2025-01-20", StartTime: "10:00", EndTime: "08:00"}, expectedFields: []string{"endTime"}},
+		{name: "mismatched hours", payload: LogPayload{PersonId: TEST_PERSON_ID, WorkDate: "2025-01-20", StartTime: "08:00", EndTime: "10:30", Hours: 3}, expectedFields: []string{"hours"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.payload.Validate()
+			if len(test.expectedFields) == 0 {
+				assertErrorKind(t, err, nil)
+				return
+			}
+			assertErrorKind(t, err, ErrValidation)
+
+			var modelError *ModelError
+			if !errors.As(err, &modelError) {
+				t.Fatalf("expected model error, got %v", err)
+			}
+			fields := make([]string, 0, len(modelError.FieldErrors))
+			for _, fieldError := range modelError.FieldErrors {
+				fields = append(fields, fieldError.Field)
+			}
+			if fmt.Sprint(fields) != fmt.Sprint(test.expectedFields) {
+				t.Fatalf("expected field errors on %v, got %v", test.expectedFields, modelError.FieldErrors)
+			}
+		})
+	}
+}
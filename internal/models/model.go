@@ -1,10 +1,13 @@
 package models
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const NUMBER_OF_PARTITION_KEY_PARTS = 2
@@ -12,10 +15,14 @@ const NUMBER_OF_SORT_KEY_PARTS = 3
 
 const SORT_KEY_VERSION_PREFIX = "V"
 
+const WORK_DATE_LOWER_BOUND = "0000-01-01"
+const WORK_DATE_UPPER_BOUND = "9999-12-31"
+
 type ModelType string
 
 const (
 	ModelTypeJob            = "Job"
+	ModelTypeJobMetadata    = "JobMetadata"
 	ModelTypeLog            = "Log"
 	ModelTypePerson         = "Person"
 	ModelTypePersonMetadata = "PersonMetadata"
@@ -26,18 +33,219 @@ type ModelIdentifiers struct {
 	PartitionId   string
 	SortType      ModelType
 	SortId        string
+	Version       int
+}
+
+const (
+	DELETED_FILTER_TRUE  = "true"
+	DELETED_FILTER_FALSE = "false"
+	DELETED_FILTER_ONLY  = "only"
+)
+
+type ModelQuery struct {
+	AsOf          time.Time
+	Limit         int
+	Cursor        string
+	Filters       map[string]string
+	Deleted       string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	WorkDateFrom  time.Time
+	WorkDateTo    time.Time
+	Sort          []SortField
+}
+
+func (q *ModelQuery) IsFiltered() bool {
+	return len(q.Filters) > 0 || (q.Deleted != "" && q.Deleted != DELETED_FILTER_TRUE) || !q.CreatedAfter.IsZero() || !q.CreatedBefore.IsZero() || q.HasWorkDateRange()
+}
+
+func (q *ModelQuery) HasWorkDateRange() bool {
+	return !q.WorkDateFrom.IsZero() || !q.WorkDateTo.IsZero()
+}
+
+func (q *ModelQuery) WorkDateBounds() (string, string) {
+	lowerBound := WORK_DATE_LOWER_BOUND
+	if !q.WorkDateFrom.IsZero() {
+		lowerBound = q.WorkDateFrom.Format(time.DateOnly)
+	}
+	upperBound := WORK_DATE_UPPER_BOUND
+	if !q.WorkDateTo.IsZero() {
+		upperBound = q.WorkDateTo.AddDate(0, 0, 1).Format(time.DateOnly)
+	}
+	return lowerBound, upperBound
+}
+
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+func ParseSort(sort string) ([]SortField, error) {
+	parts := strings.Split(sort, ",")
+	sortFields := make([]SortField, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		sortField := SortField{Field: strings.TrimLeft(part, "+-"), Descending: strings.HasPrefix(part, "-")}
+		if sortField.Field == "" || len(part)-len(sortField.Field) > 1 {
+			return nil, NewModelError(ErrValidation, "invalid sort")
+		}
+		sortFields = append(sortFields, sortField)
+	}
+	return sortFields, nil
+}
+
+func FormatSort(sortFields []SortField) string {
+	parts := make([]string, len(sortFields))
+	for idx, sortField := range sortFields {
+		parts[idx] = sortField.Field
+		if sortField.Descending {
+			parts[idx] = "-" + sortField.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func ValidateSort[D TypedModelData[D]](sortFields []SortField, modelData D) error {
+	for _, sortField := range sortFields {
+		_, ok := modelData.CompareField(modelData, sortField.Field)
+		if !ok {
+			return NewModelError(ErrValidation, fmt.Sprintf("unsupported sort field %s", sortField.Field))
+		}
+	}
+	return nil
+}
+
+type ModelPage struct {
+	Items      []ModelData `json:"items"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
+
+type TypedModelPage[D ModelData] struct {
+	Items      []D    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+func (p *TypedModelPage[D]) ModelPage() *ModelPage {
+	return &ModelPage{Items: ModelDatas(p.Items), NextCursor: p.NextCursor}
+}
+
+func ModelDatas[D ModelData](datas []D) []ModelData {
+	modelDatas := make([]ModelData, len(datas))
+	for idx, data := range datas {
+		modelDatas[idx] = data
+	}
+	return modelDatas
 }
 
 type ModelPayload interface {
+	Validate() error
 	Item(modelIdentifiers *ModelIdentifiers, version int, latestVersion int, createdAt string, createdBy string) ModelItem
 }
 
 type ModelItem interface {
 	New() ModelItem
 	Data() (ModelData, error)
+	Payload() ModelPayload
+}
+
+type TypedModelItem[D ModelData] interface {
+	ModelItem
+	TypedData() (D, error)
+}
+
+type ModelData interface {
+	Audit() ModelAudit
+}
+
+type TypedModelData[D any] interface {
+	ModelData
+	CompareField(other D, field string) (int, bool)
 }
 
-type ModelData interface{}
+type ModelAudit struct {
+	Version    int
+	CreatedAt  string
+	CreatedBy  string
+	DeletedAt  string
+	DeletedBy  string
+	RestoredAt string
+	RestoredBy string
+}
+
+func (a ModelAudit) UpdatedAt() (time.Time, error) {
+	updatedAt := a.DeletedAt
+	if updatedAt == "" {
+		updatedAt = a.CreatedAt
+	}
+	t, err := time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid updated at: %w", err)
+	}
+	return t, nil
+}
+
+func (a ModelAudit) CompareField(other ModelAudit, field string) (int, bool) {
+	switch field {
+	case "version":
+		return cmp.Compare(a.Version, other.Version), true
+	case "createdAt":
+		return strings.Compare(a.CreatedAt, other.CreatedAt), true
+	case "createdBy":
+		return strings.Compare(a.CreatedBy, other.CreatedBy), true
+	case "deletedAt":
+		return strings.Compare(a.DeletedAt, other.DeletedAt), true
+	case "deletedBy":
+		return strings.Compare(a.DeletedBy, other.DeletedBy), true
+	case "restoredAt":
+		return strings.Compare(a.RestoredAt, other.RestoredAt), true
+	case "restoredBy":
+		return strings.Compare(a.RestoredBy, other.RestoredBy), true
+	default:
+		return 0, false
+	}
+}
+
+type ModelDiff struct {
+	Field    string      `json:"field"`
+	OldValue interface{} `json:"oldValue"`
+	NewValue interface{} `json:"newValue"`
+}
+
+func DiffData(oldData ModelData, newData ModelData) ([]ModelDiff, error) {
+	oldValue := reflect.ValueOf(oldData)
+	newValue := reflect.ValueOf(newData)
+	if oldValue.Kind() != reflect.Pointer || newValue.Kind() != reflect.Pointer {
+		return nil, errors.New("model data must be pointer to struct")
+	}
+	oldValue = oldValue.Elem()
+	newValue = newValue.Elem()
+	if oldValue.Kind() != reflect.Struct || oldValue.Type() != newValue.Type() {
+		return nil, errors.New("model data must be pointers to structs of the same type")
+	}
+
+	diffs := make([]ModelDiff, 0)
+	for idx := 0; idx < oldValue.NumField(); idx++ {
+		structField := oldValue.Type().Field(idx)
+		if !structField.IsExported() {
+			continue
+		}
+
+		oldFieldValue := oldValue.Field(idx).Interface()
+		newFieldValue := newValue.Field(idx).Interface()
+		if reflect.DeepEqual(oldFieldValue, newFieldValue) {
+			continue
+		}
+
+		field, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if field == "" {
+			field = structField.Name
+		}
+
+		diffs = append(diffs, ModelDiff{Field: field, OldValue: oldFieldValue, NewValue: newFieldValue})
+	}
+
+	return diffs, nil
+}
 
 func EncodePartitionKey(partitionType ModelType, partitionId string) string {
 	return fmt.Sprintf("%s#%s", partitionType, partitionId)
@@ -51,6 +259,10 @@ func EncodeAnonymousSortKey(version int, sortType ModelType) string {
 	return fmt.Sprintf("%s%d#%s#", SORT_KEY_VERSION_PREFIX, version, sortType)
 }
 
+func EncodeWorkDateKey(workDate string, sortId string) string {
+	return fmt.Sprintf("%s#%s", workDate, sortId)
+}
+
 func DecodePartitionKey(partitionKey string) (ModelType, string, error) {
 	parts := strings.Split(partitionKey, "#")
 	if len(parts) != NUMBER_OF_PARTITION_KEY_PARTS {
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func assertErrorKind(t *testing.T, err error, expectedKind error) {
+	t.Helper()
+	if expectedKind == nil {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return
+	}
+	if !errors.Is(err, expectedKind) {
+		t.Fatalf("expected %v, got %v", expectedKind, err)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name               string
+		sort               string
+		expectedKind       error
+		expectedSortFields []SortField
+	}{
+		{name: "ascending", sort: "workDate", expectedSortFields: []SortField{{Field: "workDate"}}},
+		{name: "explicit ascending", sort: "+workDate", expectedSortFields: []SortField{{Field: "workDate"}}},
+		{name: "descending", sort: "-hours", expectedSortFields: []SortField{{Field: "hours", Descending: true}}},
+		{name: "many fields", sort: "personId, -workDate,hours", expectedSortFields: []SortField{{Field: "personId"}, {Field: "workDate", Descending: true}, {Field: "hours"}}},
+		{name: "empty", sort: "", expectedKind: ErrValidation},
+		{name: "empty field", sort: "workDate,,hours", expectedKind: ErrValidation},
+		{name: "sign only", sort: "-", expectedKind: ErrValidation},
+		{name: "many signs", sort: "--hours", expectedKind: ErrValidation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sortFields, err := ParseSort(test.sort)
+			assertErrorKind(t, err, test.expectedKind)
+			if fmt.Sprint(sortFields) != fmt.Sprint(test.expectedSortFields) {
+				t.Fatalf("expected sort fields %v, got %v", test.expectedSortFields, sortFields)
+			}
+			if test.expectedKind == nil && FormatSort(sortFields) != FormatSort(test.expectedSortFields) {
+				t.Fatalf("expected formatted sort %q, got %q", FormatSort(test.expectedSortFields), FormatSort(sortFields))
+			}
+		})
+	}
+}
+
+func TestDiffData(t *testing.T) {
+	tests := []struct {
+		name          string
+		oldData       ModelData
+		newData       ModelData
+		expectedError bool
+		expectedDiffs []ModelDiff
+	}{
+		{name: "same", oldData: &LogData{Hours: 2, Version: 1}, newData: &LogData{Hours: 2, Version: 1}, expectedDiffs: []ModelDiff{}},
+		{
+			name:          "changed fields",
+			oldData:       &LogData{WorkDate: "2025-01-20", Hours: 2, Version: 1},
+			newData:       &LogData{WorkDate: "2025-01-21", Hours: 2.5, Version: 2},
+			expectedDiffs: []ModelDiff{{Field: "workDate", OldValue: "2025-01-20", NewValue: "2025-01-21"}, {Field: "hours", OldValue: 2.0, NewValue: 2.5}, {Field: "version", OldValue: 1, NewValue: 2}},
+		},
+		{name: "different types", oldData: &LogData{}, newData: &JobMetadataData{}, expectedError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diffs, err := DiffData(test.oldData, test.newData)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("expected error %t, got %v", test.expectedError, err)
+			}
+			if !reflect.DeepEqual(diffs, test.expectedDiffs) {
+				t.Fatalf("expected diffs %v, got %v", test.expectedDiffs, diffs)
+			}
+		})
+	}
+}
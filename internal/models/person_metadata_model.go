@@ -1,10 +1,21 @@
 package models
 
+import "strings"
+
 type PersonMetadataPayload struct {
 	GivenName  string `json:"givenName"`
 	FamilyName string `json:"familyName"`
 }
 
+func (p *PersonMetadataPayload) Validate() error {
+	v := new(Validator)
+	v.Required("givenName", p.GivenName)
+	v.MaxLength("givenName", p.GivenName, 100)
+	v.Required("familyName", p.FamilyName)
+	v.MaxLength("familyName", p.FamilyName, 100)
+	return v.Error()
+}
+
 func (p *PersonMetadataPayload) Item(modelIdentifiers *ModelIdentifiers, version int, latestVersion int, createdAt string, createdBy string) ModelItem {
 	return &PersonMetadataItem{
 		GivenName:     p.GivenName,
@@ -17,6 +28,8 @@ func (p *PersonMetadataPayload) Item(modelIdentifiers *ModelIdentifiers, version
 		CreatedBy:     createdBy,
 		DeletedAt:     "",
 		DeletedBy:     "",
+		RestoredAt:    "",
+		RestoredBy:    "",
 	}
 }
 
@@ -31,6 +44,8 @@ type PersonMetadataItem struct {
 	CreatedBy     string
 	DeletedAt     string `dynamodbav:",omitempty"`
 	DeletedBy     string `dynamodbav:",omitempty"`
+	RestoredAt    string `dynamodbav:",omitempty"`
+	RestoredBy    string `dynamodbav:",omitempty"`
 }
 
 func (i *PersonMetadataItem) New() ModelItem {
@@ -38,28 +53,78 @@ func (i *PersonMetadataItem) New() ModelItem {
 }
 
 func (i *PersonMetadataItem) Data() (ModelData, error) {
+	return i.TypedData()
+}
+
+func (i *PersonMetadataItem) TypedData() (*PersonMetadataData, error) {
 	_, partitionId, err := DecodePartitionKey(i.PK)
 	if err != nil {
 		return nil, err
 	}
 
+	version, _, _, err := DecodeSortKey(i.SK)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		version = i.LatestVersion
+	}
+
 	return &PersonMetadataData{
 		GivenName:  i.GivenName,
 		FamilyName: i.FamilyName,
 		PersonId:   partitionId,
+		Version:    version,
 		CreatedAt:  i.CreatedAt,
 		CreatedBy:  i.CreatedBy,
 		DeletedAt:  i.DeletedAt,
 		DeletedBy:  i.DeletedBy,
+		RestoredAt: i.RestoredAt,
+		RestoredBy: i.RestoredBy,
 	}, nil
 }
 
+func (i *PersonMetadataItem) Payload() ModelPayload {
+	return &PersonMetadataPayload{
+		GivenName:  i.GivenName,
+		FamilyName: i.FamilyName,
+	}
+}
+
 type PersonMetadataData struct {
 	GivenName  string `json:"givenName"`
 	FamilyName string `json:"familyName"`
 	PersonId   string `json:"personId"`
+	Version    int    `json:"version"`
 	CreatedAt  string `json:"createdAt"`
 	CreatedBy  string `json:"createdBy"`
 	DeletedAt  string `json:"deletedAt"`
 	DeletedBy  string `json:"deletedBy"`
+	RestoredAt string `json:"restoredAt"`
+	RestoredBy string `json:"restoredBy"`
+}
+
+func (d *PersonMetadataData) Audit() ModelAudit {
+	return ModelAudit{
+		Version:    d.Version,
+		CreatedAt:  d.CreatedAt,
+		CreatedBy:  d.CreatedBy,
+		DeletedAt:  d.DeletedAt,
+		DeletedBy:  d.DeletedBy,
+		RestoredAt: d.RestoredAt,
+		RestoredBy: d.RestoredBy,
+	}
+}
+
+func (d *PersonMetadataData) CompareField(other *PersonMetadataData, field string) (int, bool) {
+	switch field {
+	case "givenName":
+		return strings.Compare(d.GivenName, other.GivenName), true
+	case "familyName":
+		return strings.Compare(d.FamilyName, other.FamilyName), true
+	case "personId":
+		return strings.Compare(d.PersonId, other.PersonId), true
+	default:
+		return d.Audit().CompareField(other.Audit(), field)
+	}
 }
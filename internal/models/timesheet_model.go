@@ -0,0 +1,118 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const TIMESHEET_DAYS = 7
+
+type Timesheet struct {
+	WeekOf     string         `json:"weekOf"`
+	Days       []string       `json:"days"`
+	Rows       []TimesheetRow `json:"rows"`
+	TotalHours float64        `json:"totalHours"`
+}
+
+type TimesheetRow struct {
+	PersonId   string            `json:"personId"`
+	PersonName string            `json:"personName"`
+	Hours      []float64         `json:"hours"`
+	TotalHours float64           `json:"totalHours"`
+	Jobs       []TimesheetJobRow `json:"jobs"`
+}
+
+type TimesheetJobRow struct {
+	JobId      string    `json:"jobId"`
+	JobName    string    `json:"jobName"`
+	Hours      []float64 `json:"hours"`
+	TotalHours float64   `json:"totalHours"`
+}
+
+func WeekStart(weekOf time.Time) time.Time {
+	return weekOf.AddDate(0, 0, -((int(weekOf.Weekday()) + 6) % 7))
+}
+
+func BuildTimesheet(weekStart time.Time, logDatas []*LogData, personMetadataDatas []*PersonMetadataData, jobMetadataDatas []*JobMetadataData) *Timesheet {
+	personNames := make(map[string]string)
+	for _, personMetadataData := range personMetadataDatas {
+		personNames[personMetadataData.PersonId] = strings.TrimSpace(personMetadataData.GivenName + " " + personMetadataData.FamilyName)
+	}
+
+	jobNames := make(map[string]string)
+	for _, jobMetadataData := range jobMetadataDatas {
+		jobNames[jobMetadataData.JobId] = jobMetadataData.Name
+	}
+
+	days := make([]string, TIMESHEET_DAYS)
+	dayIndexes := make(map[string]int)
+	for idx := range days {
+		days[idx] = weekStart.AddDate(0, 0, idx).Format(time.DateOnly)
+		dayIndexes[days[idx]] = idx
+	}
+
+	timesheet := &Timesheet{WeekOf: days[0], Days: days, Rows: make([]TimesheetRow, 0)}
+	rowIndexes := make(map[string]int)
+	jobRowIndexes := make(map[string]map[string]int)
+	for _, logData := range logDatas {
+		if logData.DeletedAt != "" {
+			continue
+		}
+
+		dayIndex, ok := dayIndexes[logData.WorkDate]
+		if !ok {
+			continue
+		}
+
+		rowIndex, ok := rowIndexes[logData.PersonId]
+		if !ok {
+			rowIndex = len(timesheet.Rows)
+			rowIndexes[logData.PersonId] = rowIndex
+			jobRowIndexes[logData.PersonId] = make(map[string]int)
+			timesheet.Rows = append(timesheet.Rows, TimesheetRow{
+				PersonId:   logData.PersonId,
+				PersonName: personNames[logData.PersonId],
+				Hours:      make([]float64, TIMESHEET_DAYS),
+				Jobs:       make([]TimesheetJobRow, 0),
+			})
+		}
+		row := &timesheet.Rows[rowIndex]
+
+		jobRowIndex, ok := jobRowIndexes[logData.PersonId][logData.JobId]
+		if !ok {
+			jobRowIndex = len(row.Jobs)
+			jobRowIndexes[logData.PersonId][logData.JobId] = jobRowIndex
+			row.Jobs = append(row.Jobs, TimesheetJobRow{
+				JobId:   logData.JobId,
+				JobName: jobNames[logData.JobId],
+				Hours:   make([]float64, TIMESHEET_DAYS),
+			})
+		}
+		jobRow := &row.Jobs[jobRowIndex]
+
+		jobRow.Hours[dayIndex] += logData.Hours
+		jobRow.TotalHours += logData.Hours
+		row.Hours[dayIndex] += logData.Hours
+		row.TotalHours += logData.Hours
+		timesheet.TotalHours += logData.Hours
+	}
+
+	for idx := range timesheet.Rows {
+		jobs := timesheet.Rows[idx].Jobs
+		sort.SliceStable(jobs, func(i, j int) bool {
+			if jobs[i].JobName != jobs[j].JobName {
+				return jobs[i].JobName < jobs[j].JobName
+			}
+			return jobs[i].JobId < jobs[j].JobId
+		})
+	}
+	sort.SliceStable(timesheet.Rows, func(i, j int) bool {
+		if timesheet.Rows[i].PersonName != timesheet.Rows[j].PersonName {
+			return timesheet.Rows[i].PersonName < timesheet.Rows[j].PersonName
+		}
+		return timesheet.Rows[i].PersonId < timesheet.Rows[j].PersonId
+	})
+
+	return timesheet
+}
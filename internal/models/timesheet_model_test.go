@@ -0,0 +1,101 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		name              string
+		weekOf            string
+		expectedWeekStart string
+	}{
+		{name: "monday", weekOf: "2025-01-20", expectedWeekStart: "2025-01-20"},
+		{name: "wednesday", weekOf: "2025-01-22", expectedWeekStart: "2025-01-20"},
+		{name: "sunday", weekOf: "2025-01-26", expectedWeekStart: "2025-01-20"},
+		{name: "across month", weekOf: "2025-02-01", expectedWeekStart: "2025-01-27"},
+		{name: "across year", weekOf: "2025-01-01", expectedWeekStart: "2024-12-30"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			weekOf, err := time.Parse(time.DateOnly, test.weekOf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			weekStart := WeekStart(weekOf).Format(time.DateOnly)
+			if weekStart != test.expectedWeekStart {
+				t.Fatalf("expected week start %s, got %s", test.expectedWeekStart, weekStart)
+			}
+		})
+	}
+}
+
+func TestBuildTimesheet(t *testing.T) {
+	weekStart, err := time.Parse(time.DateOnly, "2025-01-20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedDays := []string{"2025-01-20", "2025-01-21", "2025-01-22", "2025-01-23", "2025-01-24", "2025-01-25", "2025-01-26"}
+	personMetadataDatas := []*PersonMetadataData{{PersonId: "p1", GivenName: "Zoe", FamilyName: "Able"}, {PersonId: "p2", GivenName: "Adam", FamilyName: "Baker"}}
+	jobMetadataDatas := []*JobMetadataData{{JobId: "j1", Name: "Porch"}, {JobId: "j2", Name: "Deck"}}
+
+	tests := []struct {
+		name              string
+		logDatas          []*LogData
+		expectedTimesheet *Timesheet
+	}{
+		{
+			name:              "no logs",
+			logDatas:          []*LogData{},
+			expectedTimesheet: &Timesheet{WeekOf: "2025-01-20", Days: expectedDays, Rows: []TimesheetRow{}},
+		},
+		{
+			name: "skips deleted logs and other weeks",
+			logDatas: []*LogData{
+				{PersonId: "p1", JobId: "j1", WorkDate: "2025-01-20", Hours: 2, DeletedAt: "2025-01-21T00:00:00Z"},
+				{PersonId: "p1", JobId: "j1", WorkDate: "2025-01-19", Hours: 3},
+				{PersonId: "p1", JobId: "j1", WorkDate: "2025-01-27", Hours: 4},
+			},
+			expectedTimesheet: &Timesheet{WeekOf: "2025-01-20", Days: expectedDays, Rows: []TimesheetRow{}},
+		},
+		{
+			name: "sums per person and job",
+			logDatas: []*LogData{
+				{PersonId: "p1", JobId: "j1", WorkDate: "2025-01-20", Hours: 2},
+				{PersonId: "p1", JobId: "j1", WorkDate: "2025-01-20", Hours: 1.5},
+				{PersonId: "p1", JobId: "j2", WorkDate: "2025-01-26", Hours: 4},
+				{PersonId: "p2", JobId: "j1", WorkDate: "2025-01-22", Hours: 8},
+				{PersonId: "p3", JobId: "j3", WorkDate: "2025-01-21", Hours: 1},
+			},
+			expectedTimesheet: &Timesheet{
+				WeekOf: "2025-01-20",
+				Days:   expectedDays,
+				Rows: []TimesheetRow{
+					{PersonId: "p3", Hours: []float64{0, 1, 0, 0, 0, 0, 0}, TotalHours: 1, Jobs: []TimesheetJobRow{
+						{JobId: "j3", Hours: []float64{0, 1, 0, 0, 0, 0, 0}, TotalHours: 1},
+					}},
+					{PersonId: "p2", PersonName: "Adam Baker", Hours: []float64{0, 0, 8, 0, 0, 0, 0}, TotalHours: 8, Jobs: []TimesheetJobRow{
+						{JobId: "j1", JobName: "Porch", Hours: []float64{0, 0, 8, 0, 0, 0, 0}, TotalHours: 8},
+					}},
+					{PersonId: "p1", PersonName: "Zoe Able", Hours: []float64{3.5, 0, 0, 0, 0, 0, 4}, TotalHours: 7.5, Jobs: []TimesheetJobRow{
+						{JobId: "j2", JobName: "Deck", Hours: []float64{0, 0, 0, 0, 0, 0, 4}, TotalHours: 4},
+						{JobId: "j1", JobName: "Porch", Hours: []float64{3.5, 0, 0, 0, 0, 0, 0}, TotalHours: 3.5},
+					}},
+				},
+				TotalHours: 16.5,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timesheet := BuildTimesheet(weekStart, test.logDatas, personMetadataDatas, jobMetadataDatas)
+			if !reflect.DeepEqual(timesheet, test.expectedTimesheet) {
+				t.Fatalf("expected timesheet %+v, got %+v", test.expectedTimesheet, timesheet)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type Validator struct {
+	FieldErrors []FieldError
+}
+
+func (v *Validator) Check(ok bool, field string, message string) {
+	if !ok {
+		v.FieldErrors = append(v.FieldErrors, FieldError{Field: field, Message: message})
+	}
+}
+
+func (v *Validator) Required(field string, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+func (v *Validator) MaxLength(field string, value string, maxLength int) {
+	v.Check(len(value) <= maxLength, field, fmt.Sprintf("must be at most %d characters", maxLength))
+}
+
+func (v *Validator) UUID(field string, value string) {
+	v.Check(uuidRegexp.MatchString(value), field, "must be a UUID")
+}
+
+func (v *Validator) Date(field string, value string) {
+	_, err := time.Parse(time.DateOnly, value)
+	v.Check(err == nil, field, "must be a date formatted as YYYY-MM-DD")
+}
+
+func (v *Validator) Time(field string, value string) {
+	_, err := time.Parse(TIME_OF_DAY_LAYOUT, value)
+	v.Check(err == nil, field, "must be a time formatted as HH:MM")
+}
+
+func (v *Validator) Range(field string, value float64, min float64, max float64) {
+	v.Check(value >= min && value <= max, field, fmt.Sprintf("must be between %g and %g", min, max))
+}
+
+func (v *Validator) Error() error {
+	if len(v.FieldErrors) == 0 {
+		return nil
+	}
+	return &ModelError{Kind: ErrValidation, Message: "invalid request body", FieldErrors: v.FieldErrors}
+}
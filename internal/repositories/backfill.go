@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"j-and-a/internal/models"
+)
+
+func needsBackfill(item map[string]types.AttributeValue) bool {
+	if _, hasFirstCreatedAt := item["FirstCreatedAt"]; !hasFirstCreatedAt {
+		return true
+	}
+	if itemString(item, "ModelType") != string(models.ModelTypeLog) {
+		return false
+	}
+	_, hasWorkDateKey := item["WorkDateKey"]
+	return !hasWorkDateKey
+}
+
+func backfillAttributes(rootItem map[string]types.AttributeValue, firstItem map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	createdAt := itemString(rootItem, "CreatedAt")
+	if firstItem != nil {
+		createdAt = itemString(firstItem, "CreatedAt")
+	}
+
+	attributes := make(map[string]types.AttributeValue)
+	if _, hasFirstCreatedAt := rootItem["FirstCreatedAt"]; !hasFirstCreatedAt {
+		attributes["FirstCreatedAt"] = &types.AttributeValueMemberS{Value: createdAt}
+	}
+
+	if _, hasWorkDateKey := rootItem["WorkDateKey"]; itemString(rootItem, "ModelType") == string(models.ModelTypeLog) && !hasWorkDateKey {
+		_, _, sortId, err := models.DecodeSortKey(itemString(rootItem, "SK"))
+		if err != nil {
+			return nil, err
+		}
+
+		workDate := itemString(rootItem, "WorkDate")
+		if workDate == "" {
+			createdAtTime, err := time.Parse(time.RFC3339, createdAt)
+			if err != nil {
+				return nil, fmt.Errorf("invalid created at for %s %s: %w", itemString(rootItem, "PK"), itemString(rootItem, "SK"), err)
+			}
+			workDate = createdAtTime.Format(time.DateOnly)
+		}
+
+		attributes["WorkDate"] = &types.AttributeValueMemberS{Value: workDate}
+		attributes["WorkDateKey"] = &types.AttributeValueMemberS{Value: models.EncodeWorkDateKey(workDate, sortId)}
+	}
+
+	return attributes, nil
+}
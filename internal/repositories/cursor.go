@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"j-and-a/internal/models"
+)
+
+type keyCursor struct {
+	Scope string            `json:"scope"`
+	Key   map[string]string `json:"key"`
+}
+
+type offsetCursor struct {
+	Scope  string `json:"scope"`
+	Offset int    `json:"offset"`
+}
+
+func EncodeCursor(lastEvaluatedKey map[string]types.AttributeValue, scope string, signingKey []byte) (string, error) {
+	if lastEvaluatedKey == nil {
+		return "", nil
+	}
+
+	key := make(map[string]string, len(lastEvaluatedKey))
+	err := attributevalue.UnmarshalMap(lastEvaluatedKey, &key)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeSignedCursor(&keyCursor{Scope: scope, Key: key}, signingKey)
+}
+
+func DecodeCursor(cursor string, scope string, signingKey []byte) (map[string]types.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	decodedCursor := new(keyCursor)
+	err := decodeSignedCursor(cursor, signingKey, decodedCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if decodedCursor.Scope != scope || len(decodedCursor.Key) == 0 {
+		return nil, models.NewModelError(models.ErrValidation, "invalid cursor")
+	}
+
+	return attributevalue.MarshalMap(decodedCursor.Key)
+}
+
+func cursorScope(modelQuery *models.ModelQuery, keyConditions ...string) string {
+	workDateLowerBound, workDateUpperBound := modelQuery.WorkDateBounds()
+	parts := append([]string{}, keyConditions...)
+	parts = append(parts,
+		"deleted="+modelQuery.Deleted,
+		"createdAfter="+formatScopeTime(modelQuery.CreatedAfter),
+		"createdBefore="+formatScopeTime(modelQuery.CreatedBefore),
+		"workDate="+workDateLowerBound+".."+workDateUpperBound,
+		"sort="+models.FormatSort(modelQuery.Sort),
+	)
+
+	filters := make([]string, 0, len(modelQuery.Filters))
+	for key, value := range modelQuery.Filters {
+		filters = append(filters, strings.ToLower(key)+"="+value)
+	}
+	sort.Strings(filters)
+	parts = append(parts, filters...)
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func formatScopeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func EncodeOffsetCursor(offset int, scope string, signingKey []byte) (string, error) {
+	return encodeSignedCursor(&offsetCursor{Scope: scope, Offset: offset}, signingKey)
+}
+
+func DecodeOffsetCursor(cursor string, scope string, signingKey []byte) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	decodedCursor := new(offsetCursor)
+	err := decodeSignedCursor(cursor, signingKey, decodedCursor)
+	if err != nil {
+		return 0, err
+	}
+
+	if decodedCursor.Scope != scope || decodedCursor.Offset < 0 {
+		return 0, models.NewModelError(models.ErrValidation, "invalid cursor")
+	}
+
+	return decodedCursor.Offset, nil
+}
+
+func encodeSignedCursor(payload interface{}, signingKey []byte) (string, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write(payloadBytes)
+
+	return base64.RawURLEncoding.EncodeToString(payloadBytes) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func decodeSignedCursor(cursor string, signingKey []byte, payload interface{}) error {
+	encodedPayload, encodedSignature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return models.NewModelError(models.ErrValidation, "invalid cursor")
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return models.NewModelError(models.ErrValidation, "invalid cursor")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return models.NewModelError(models.ErrValidation, "invalid cursor")
+	}
+
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write(payloadBytes)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return models.NewModelError(models.ErrValidation, "invalid cursor")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payloadBytes))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(payload)
+	if err != nil {
+		return models.NewModelError(models.ErrValidation, "invalid cursor")
+	}
+
+	return nil
+}
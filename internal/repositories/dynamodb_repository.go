@@ -0,0 +1,732 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+
+	"j-and-a/internal/models"
+)
+
+const BATCH_GET_ITEM_LIMIT = 100
+
+type DynamoDBTable struct {
+	Client            *dynamodb.Client
+	TableName         string
+	IndexName         string
+	PersonIndexName   string
+	WorkDateIndexName string
+	CursorSigningKey  []byte
+}
+
+func (t *DynamoDBTable) BackfillRootItems(ctx context.Context) (int, error) {
+	backfilled := 0
+	var exclusiveStartKey map[string]types.AttributeValue
+	for {
+		scanOutput, err := t.Client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:        aws.String(t.TableName),
+			FilterExpression: aws.String("begins_with(SK, :SK) AND (attribute_not_exists(FirstCreatedAt) OR (ModelType = :ModelType AND attribute_not_exists(WorkDateKey)))"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":SK":        &types.AttributeValueMemberS{Value: models.SORT_KEY_VERSION_PREFIX + "0#"},
+				":ModelType": &types.AttributeValueMemberS{Value: string(models.ModelTypeLog)},
+			},
+			ExclusiveStartKey: exclusiveStartKey,
+		})
+		if err != nil {
+			return backfilled, err
+		}
+
+		for _, rootItem := range scanOutput.Items {
+			if !needsBackfill(rootItem) {
+				continue
+			}
+
+			ok, err := t.backfillRootItem(ctx, rootItem)
+			if err != nil {
+				return backfilled, err
+			}
+			if ok {
+				backfilled++
+			}
+		}
+
+		if scanOutput.LastEvaluatedKey == nil {
+			return backfilled, nil
+		}
+		exclusiveStartKey = scanOutput.LastEvaluatedKey
+	}
+}
+
+func (t *DynamoDBTable) backfillRootItem(ctx context.Context, rootItem map[string]types.AttributeValue) (bool, error) {
+	_, sortType, sortId, err := models.DecodeSortKey(itemString(rootItem, "SK"))
+	if err != nil {
+		return false, err
+	}
+
+	getItemOutput, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": rootItem["PK"],
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(1, sortType, sortId)},
+		},
+		ProjectionExpression: aws.String("CreatedAt"),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	attributes, err := backfillAttributes(rootItem, getItemOutput.Item)
+	if err != nil {
+		return false, err
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	assignments := make([]string, 0, len(names))
+	conditions := []string{"attribute_exists(PK)"}
+	expressionAttributeValues := make(map[string]types.AttributeValue, len(names))
+	for _, name := range names {
+		assignments = append(assignments, fmt.Sprintf("%s = :%s", name, name))
+		if name != "WorkDate" {
+			conditions = append(conditions, fmt.Sprintf("attribute_not_exists(%s)", name))
+		}
+		expressionAttributeValues[":"+name] = attributes[name]
+	}
+
+	_, err = t.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": rootItem["PK"],
+			"SK": rootItem["SK"],
+		},
+		UpdateExpression:          aws.String("SET " + strings.Join(assignments, ", ")),
+		ExpressionAttributeValues: expressionAttributeValues,
+		ConditionExpression:       aws.String(strings.Join(conditions, " AND ")),
+	})
+	if isConditionalCheckFailed(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (t *DynamoDBTable) DeleteByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+	getItemOutput, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+		},
+		ProjectionExpression: aws.String("LatestVersion, DeletedAt"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if getItemOutput.Item == nil {
+		return models.NewModelError(models.ErrNotFound, "item not found")
+	}
+
+	if _, ok := getItemOutput.Item["DeletedAt"]; ok {
+		return models.NewModelError(models.ErrConflict, "item already deleted")
+	}
+
+	latestVersion := 0
+	if lastedVersionAttributeValue, ok := getItemOutput.Item["LatestVersion"]; ok {
+		err = attributevalue.Unmarshal(lastedVersionAttributeValue, &latestVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	unixMilli, ok := ctx.Value("requestedAt").(int64)
+	if !ok {
+		return models.NewModelError(models.ErrInternal, "failed to parse requested at within context")
+	}
+	deletedAt := time.UnixMilli(unixMilli).Format(time.RFC3339)
+	deletedBy, ok := ctx.Value("requestedBy").(string)
+	if !ok {
+		return models.NewModelError(models.ErrForbidden, "missing requested by within context")
+	}
+
+	expectedVersion, hasExpectedVersion := ctx.Value("expectedVersion").(int)
+	if hasExpectedVersion && expectedVersion != latestVersion {
+		return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+	}
+
+	_, err = t.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Update: &types.Update{
+				TableName: &t.TableName,
+				Key: map[string]types.AttributeValue{
+					"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+					"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+				},
+				UpdateExpression: aws.String("SET DeletedAt = :DeletedAt, DeletedBy = :DeletedBy"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":DeletedAt":     &types.AttributeValueMemberS{Value: deletedAt},
+					":DeletedBy":     &types.AttributeValueMemberS{Value: deletedBy},
+					":LatestVersion": &types.AttributeValueMemberN{Value: strconv.Itoa(latestVersion)},
+				},
+				ConditionExpression: aws.String("attribute_exists(PK) AND attribute_not_exists(DeletedAt) AND LatestVersion = :LatestVersion"),
+			}},
+			{Update: &types.Update{
+				TableName: &t.TableName,
+				Key: map[string]types.AttributeValue{
+					"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+					"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(latestVersion, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+				},
+				UpdateExpression: aws.String("SET DeletedAt = :DeletedAt, DeletedBy = :DeletedBy"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":DeletedAt": &types.AttributeValueMemberS{Value: deletedAt},
+					":DeletedBy": &types.AttributeValueMemberS{Value: deletedBy},
+				},
+				ConditionExpression: aws.String("attribute_exists(PK) AND attribute_not_exists(DeletedAt)"),
+			}},
+		},
+	})
+	if isConditionalCheckFailed(err) {
+		if hasExpectedVersion {
+			return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+		}
+		return models.NewModelError(models.ErrConflict, "item was modified concurrently")
+	}
+
+	return err
+}
+
+func (t *DynamoDBTable) QueryItemsByPartitionId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error) {
+	if !modelQuery.AsOf.IsZero() {
+		return t.queryAsOf(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(t.TableName),
+			KeyConditionExpression: aws.String("PK = :PK AND begins_with(SK, :SK)"),
+			FilterExpression:       aws.String("ModelType = :ModelType"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":PK":        &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+				":SK":        &types.AttributeValueMemberS{Value: models.SORT_KEY_VERSION_PREFIX},
+				":ModelType": &types.AttributeValueMemberS{Value: string(modelIdentifiers.SortType)},
+			},
+		}, itemCodec, modelQuery)
+	}
+
+	partitionKey := models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)
+	sortKeyPrefix := models.EncodeAnonymousSortKey(0, modelIdentifiers.SortType)
+	queryInput := &dynamodb.QueryInput{
+		TableName:              aws.String(t.TableName),
+		KeyConditionExpression: aws.String("PK = :PK AND begins_with(SK, :SK)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":PK": &types.AttributeValueMemberS{Value: partitionKey},
+			":SK": &types.AttributeValueMemberS{Value: sortKeyPrefix},
+		},
+	}
+	if modelQuery.HasWorkDateRange() {
+		workDateLowerBound, workDateUpperBound := modelQuery.WorkDateBounds()
+		queryInput.FilterExpression = aws.String("WorkDate >= :WorkDateFrom AND WorkDate < :WorkDateTo")
+		queryInput.ExpressionAttributeValues[":WorkDateFrom"] = &types.AttributeValueMemberS{Value: workDateLowerBound}
+		queryInput.ExpressionAttributeValues[":WorkDateTo"] = &types.AttributeValueMemberS{Value: workDateUpperBound}
+	}
+	return t.queryPage(ctx, queryInput, itemCodec, modelQuery, cursorScope(modelQuery, "PK="+partitionKey, "SK="+sortKeyPrefix))
+}
+
+func (t *DynamoDBTable) GetItemByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (map[string]types.AttributeValue, error) {
+	getItemOutput, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if getItemOutput.Item == nil {
+		return nil, models.NewModelError(models.ErrNotFound, "item not found")
+	}
+
+	return getItemOutput.Item, nil
+}
+
+func (t *DynamoDBTable) QueryItemsByPersonId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error) {
+	err := validatePersonIdQuery(modelQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	workDateLowerBound, workDateUpperBound := modelQuery.WorkDateBounds()
+	return t.queryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(t.TableName),
+		IndexName:              aws.String(t.PersonIndexName),
+		KeyConditionExpression: aws.String("PersonId = :PersonId AND WorkDateKey BETWEEN :WorkDateFrom AND :WorkDateTo"),
+		FilterExpression:       aws.String("ModelType = :ModelType"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":PersonId":     &types.AttributeValueMemberS{Value: modelIdentifiers.PartitionId},
+			":WorkDateFrom": &types.AttributeValueMemberS{Value: workDateLowerBound},
+			":WorkDateTo":   &types.AttributeValueMemberS{Value: workDateUpperBound},
+			":ModelType":    &types.AttributeValueMemberS{Value: string(modelIdentifiers.SortType)},
+		},
+	}, itemCodec, modelQuery, cursorScope(modelQuery, "PersonId="+modelIdentifiers.PartitionId, "ModelType="+string(modelIdentifiers.SortType), "WorkDateKey"))
+}
+
+func (t *DynamoDBTable) QueryItemsBySortType(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error) {
+	if !modelQuery.AsOf.IsZero() {
+		return t.queryAsOf(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(t.TableName),
+			IndexName:              aws.String(t.IndexName),
+			KeyConditionExpression: aws.String("ModelType = :ModelType AND begins_with(SK, :SK)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":ModelType": &types.AttributeValueMemberS{Value: string(modelIdentifiers.SortType)},
+				":SK":        &types.AttributeValueMemberS{Value: models.SORT_KEY_VERSION_PREFIX},
+			},
+		}, itemCodec, modelQuery)
+	}
+
+	if modelQuery.HasWorkDateRange() {
+		workDateLowerBound, workDateUpperBound := modelQuery.WorkDateBounds()
+		return t.queryPage(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(t.TableName),
+			IndexName:              aws.String(t.WorkDateIndexName),
+			KeyConditionExpression: aws.String("ModelType = :ModelType AND WorkDateKey BETWEEN :WorkDateFrom AND :WorkDateTo"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":ModelType":    &types.AttributeValueMemberS{Value: string(modelIdentifiers.SortType)},
+				":WorkDateFrom": &types.AttributeValueMemberS{Value: workDateLowerBound},
+				":WorkDateTo":   &types.AttributeValueMemberS{Value: workDateUpperBound},
+			},
+		}, itemCodec, modelQuery, cursorScope(modelQuery, "ModelType="+string(modelIdentifiers.SortType), "WorkDateKey"))
+	}
+
+	sortKeyPrefix := models.EncodeAnonymousSortKey(0, modelIdentifiers.SortType)
+	return t.queryPage(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(t.TableName),
+		IndexName:              aws.String(t.IndexName),
+		KeyConditionExpression: aws.String("ModelType = :ModelType AND begins_with(SK, :SK)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ModelType": &types.AttributeValueMemberS{Value: string(modelIdentifiers.SortType)},
+			":SK":        &types.AttributeValueMemberS{Value: sortKeyPrefix},
+		},
+	}, itemCodec, modelQuery, cursorScope(modelQuery, "ModelType="+string(modelIdentifiers.SortType), "SK="+sortKeyPrefix))
+}
+
+func (t *DynamoDBTable) GetVersionItemByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (map[string]types.AttributeValue, error) {
+	getItemOutput, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(modelIdentifiers.Version, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if getItemOutput.Item == nil {
+		return nil, models.NewModelError(models.ErrNotFound, "version not found")
+	}
+
+	return getItemOutput.Item, nil
+}
+
+func (t *DynamoDBTable) GetVersionItemsByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) ([]map[string]types.AttributeValue, error) {
+	getItemOutput, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+		},
+		ProjectionExpression: aws.String("LatestVersion"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if getItemOutput.Item == nil {
+		return nil, models.NewModelError(models.ErrNotFound, "item not found")
+	}
+
+	latestVersion := 0
+	if lastedVersionAttributeValue, ok := getItemOutput.Item["LatestVersion"]; ok {
+		err = attributevalue.Unmarshal(lastedVersionAttributeValue, &latestVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]map[string]types.AttributeValue, 0, latestVersion)
+	for sortKeyVersion := latestVersion; sortKeyVersion > 0; sortKeyVersion-- {
+		keys = append(keys, map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(sortKeyVersion, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+		})
+	}
+
+	items := make([]map[string]types.AttributeValue, 0, latestVersion)
+	for start := 0; start < len(keys); start += BATCH_GET_ITEM_LIMIT {
+		requestItems := map[string]types.KeysAndAttributes{
+			t.TableName: {Keys: keys[start:min(start+BATCH_GET_ITEM_LIMIT, len(keys))]},
+		}
+		for len(requestItems) > 0 {
+			batchGetItemOutput, err := t.Client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: requestItems,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, batchGetItemOutput.Responses[t.TableName]...)
+
+			requestItems = batchGetItemOutput.UnprocessedKeys
+		}
+	}
+
+	return items, nil
+}
+
+func (t *DynamoDBTable) PutByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelPayload models.ModelPayload) error {
+	return t.put(ctx, modelIdentifiers, modelPayload, false)
+}
+
+func (t *DynamoDBTable) put(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelPayload models.ModelPayload, requireUndeleted bool) error {
+	getItemOutput, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+		},
+		ProjectionExpression: aws.String("LatestVersion, DeletedAt, FirstCreatedAt"),
+	})
+	if err != nil {
+		return err
+	}
+
+	latestVersion := 0
+	if lastedVersionAttributeValue, ok := getItemOutput.Item["LatestVersion"]; ok {
+		err = attributevalue.Unmarshal(lastedVersionAttributeValue, &latestVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	unixMilli, ok := ctx.Value("requestedAt").(int64)
+	if !ok {
+		return models.NewModelError(models.ErrInternal, "failed to parse requested at within context")
+	}
+	createdAt := time.UnixMilli(unixMilli).Format(time.RFC3339)
+	createdBy, ok := ctx.Value("requestedBy").(string)
+	if !ok {
+		return models.NewModelError(models.ErrForbidden, "missing requested by within context")
+	}
+
+	expectedVersion, hasExpectedVersion := ctx.Value("expectedVersion").(int)
+	if hasExpectedVersion && expectedVersion != latestVersion {
+		return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+	}
+
+	_, isDeleted := getItemOutput.Item["DeletedAt"]
+	if hasExpectedVersion && isDeleted {
+		return models.NewModelError(models.ErrPreconditionFailed, "item deleted")
+	}
+	if requireUndeleted && isDeleted {
+		return models.NewModelError(models.ErrConflict, "item deleted")
+	}
+
+	rootItem, err := attributevalue.MarshalMap(modelPayload.Item(modelIdentifiers, 0, latestVersion+1, createdAt, createdBy))
+	if err != nil {
+		return err
+	}
+	setFirstCreatedAt(rootItem, getItemOutput.Item, createdAt)
+
+	item, err := attributevalue.MarshalMap(modelPayload.Item(modelIdentifiers, latestVersion+1, 0, createdAt, createdBy))
+	if err != nil {
+		return err
+	}
+
+	rootPut := &types.Put{
+		TableName:           &t.TableName,
+		Item:                rootItem,
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+	}
+	if latestVersion > 0 {
+		rootPut.ConditionExpression = aws.String("LatestVersion = :LatestVersion")
+		rootPut.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":LatestVersion": &types.AttributeValueMemberN{Value: strconv.Itoa(latestVersion)},
+		}
+		if hasExpectedVersion || requireUndeleted {
+			rootPut.ConditionExpression = aws.String("LatestVersion = :LatestVersion AND attribute_not_exists(DeletedAt)")
+		}
+	}
+
+	_, err = t.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: rootPut},
+			{Put: &types.Put{
+				TableName:           &t.TableName,
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(SK)"),
+			}},
+		},
+	})
+	if isConditionalCheckFailed(err) {
+		if hasExpectedVersion {
+			return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+		}
+		return models.NewModelError(models.ErrConflict, "item was modified concurrently")
+	}
+
+	return err
+}
+
+func (t *DynamoDBTable) RestoreByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+	getItemOutput, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if getItemOutput.Item == nil {
+		return models.NewModelError(models.ErrNotFound, "item not found")
+	}
+
+	if _, ok := getItemOutput.Item["DeletedAt"]; !ok {
+		return models.NewModelError(models.ErrConflict, "item not deleted")
+	}
+
+	latestVersion := 0
+	if lastedVersionAttributeValue, ok := getItemOutput.Item["LatestVersion"]; ok {
+		err = attributevalue.Unmarshal(lastedVersionAttributeValue, &latestVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	unixMilli, ok := ctx.Value("requestedAt").(int64)
+	if !ok {
+		return models.NewModelError(models.ErrInternal, "failed to parse requested at within context")
+	}
+	restoredAt := time.UnixMilli(unixMilli).Format(time.RFC3339)
+	restoredBy, ok := ctx.Value("requestedBy").(string)
+	if !ok {
+		return models.NewModelError(models.ErrForbidden, "missing requested by within context")
+	}
+
+	expectedVersion, hasExpectedVersion := ctx.Value("expectedVersion").(int)
+	if hasExpectedVersion && expectedVersion != latestVersion {
+		return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+	}
+
+	rootItem := getItemOutput.Item
+	delete(rootItem, "DeletedAt")
+	delete(rootItem, "DeletedBy")
+	rootItem["RestoredAt"] = &types.AttributeValueMemberS{Value: restoredAt}
+	rootItem["RestoredBy"] = &types.AttributeValueMemberS{Value: restoredBy}
+
+	item := make(map[string]types.AttributeValue, len(rootItem))
+	for key, attributeValue := range rootItem {
+		item[key] = attributeValue
+	}
+	delete(item, "LatestVersion")
+	delete(item, "WorkDateKey")
+	delete(item, "FirstCreatedAt")
+	item["SK"] = &types.AttributeValueMemberS{Value: models.EncodeSortKey(latestVersion+1, modelIdentifiers.SortType, modelIdentifiers.SortId)}
+
+	rootItem["LatestVersion"], err = attributevalue.Marshal(latestVersion + 1)
+	if err != nil {
+		return err
+	}
+
+	latestVersionAttributeValue, err := attributevalue.Marshal(latestVersion)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:           &t.TableName,
+				Item:                rootItem,
+				ConditionExpression: aws.String("attribute_exists(DeletedAt) AND LatestVersion = :LatestVersion"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":LatestVersion": latestVersionAttributeValue,
+				},
+			}},
+			{Put: &types.Put{
+				TableName:           &t.TableName,
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(SK)"),
+			}},
+		},
+	})
+	if isConditionalCheckFailed(err) {
+		if hasExpectedVersion {
+			return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+		}
+		return models.NewModelError(models.ErrConflict, "item was modified concurrently")
+	}
+
+	return err
+}
+
+func (t *DynamoDBTable) RevertItemByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec) error {
+	rootGetItemOutput, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+		},
+		ProjectionExpression: aws.String("DeletedAt"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, ok := rootGetItemOutput.Item["DeletedAt"]; ok {
+		return models.NewModelError(models.ErrConflict, "item deleted")
+	}
+
+	getItemOutput, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
+			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(modelIdentifiers.Version, modelIdentifiers.SortType, modelIdentifiers.SortId)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if getItemOutput.Item == nil {
+		return models.NewModelError(models.ErrNotFound, "version not found")
+	}
+
+	modelItem := itemCodec.NewModelItem()
+	err = attributevalue.UnmarshalMap(getItemOutput.Item, modelItem)
+	if err != nil {
+		return err
+	}
+
+	modelPayload := modelItem.Payload()
+	err = modelPayload.Validate()
+	if err != nil {
+		return err
+	}
+
+	return t.put(ctx, modelIdentifiers, modelPayload, true)
+}
+
+func (t *DynamoDBTable) queryPage(ctx context.Context, queryInput *dynamodb.QueryInput, itemCodec ItemCodec, modelQuery *models.ModelQuery, scope string) (*ItemPage, error) {
+	err := applyFilterExpression(queryInput, itemCodec.NewModelItem(), modelQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modelQuery.Sort) > 0 {
+		return t.querySorted(ctx, queryInput, itemCodec, modelQuery, scope)
+	}
+
+	exclusiveStartKey, err := DecodeCursor(modelQuery.Cursor, scope, t.CursorSigningKey)
+	if err != nil {
+		return nil, err
+	}
+	queryInput.ExclusiveStartKey = exclusiveStartKey
+
+	items := make([]map[string]types.AttributeValue, 0, modelQuery.Limit)
+	var lastEvaluatedKey map[string]types.AttributeValue
+	for {
+		if modelQuery.Limit > 0 {
+			queryInput.Limit = aws.Int32(int32(modelQuery.Limit - len(items)))
+		}
+
+		queryOutput, err := t.Client.Query(ctx, queryInput)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, queryOutput.Items...)
+
+		lastEvaluatedKey = queryOutput.LastEvaluatedKey
+		if lastEvaluatedKey == nil || modelQuery.Limit == 0 || len(items) >= modelQuery.Limit {
+			break
+		}
+		queryInput.ExclusiveStartKey = lastEvaluatedKey
+	}
+
+	nextCursor, err := EncodeCursor(lastEvaluatedKey, scope, t.CursorSigningKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ItemPage{Items: items, NextCursor: nextCursor}, nil
+}
+
+func (t *DynamoDBTable) querySorted(ctx context.Context, queryInput *dynamodb.QueryInput, itemCodec ItemCodec, modelQuery *models.ModelQuery, scope string) (*ItemPage, error) {
+	items := make([]map[string]types.AttributeValue, 0)
+	for {
+		queryOutput, err := t.Client.Query(ctx, queryInput)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, queryOutput.Items...)
+
+		if queryOutput.LastEvaluatedKey == nil || len(items) > SORT_ITEM_LIMIT {
+			break
+		}
+		queryInput.ExclusiveStartKey = queryOutput.LastEvaluatedKey
+	}
+
+	return pageSorted(items, itemCodec, modelQuery, scope, t.CursorSigningKey)
+}
+
+func (t *DynamoDBTable) queryAsOf(ctx context.Context, queryInput *dynamodb.QueryInput, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error) {
+	err := validateAsOf(modelQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]map[string]types.AttributeValue, 0)
+	for {
+		queryOutput, err := t.Client.Query(ctx, queryInput)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, queryOutput.Items...)
+
+		if queryOutput.LastEvaluatedKey == nil {
+			break
+		}
+		queryInput.ExclusiveStartKey = queryOutput.LastEvaluatedKey
+	}
+
+	return pageAsOf(items, itemCodec, modelQuery)
+}
+
+func isConditionalCheckFailed(err error) bool {
+	var transactionCanceledException *types.TransactionCanceledException
+	if !errors.As(err, &transactionCanceledException) {
+		return false
+	}
+	for _, cancellationReason := range transactionCanceledException.CancellationReasons {
+		if aws.StringValue(cancellationReason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+
+	"j-and-a/internal/models"
+)
+
+var unfilterableAttributes = map[string]bool{"PK": true, "SK": true, "ModelType": true, "WorkDateKey": true, "LatestVersion": true}
+
+func applyFilterExpression(queryInput *dynamodb.QueryInput, modelItem models.ModelItem, modelQuery *models.ModelQuery) error {
+	conditions := make([]string, 0)
+	expressionAttributeNames := make(map[string]string)
+	expressionAttributeValues := make(map[string]types.AttributeValue)
+
+	switch modelQuery.Deleted {
+	case models.DELETED_FILTER_FALSE:
+		conditions = append(conditions, "attribute_not_exists(DeletedAt)")
+	case models.DELETED_FILTER_ONLY:
+		conditions = append(conditions, "attribute_exists(DeletedAt)")
+	}
+
+	if !modelQuery.CreatedAfter.IsZero() {
+		conditions = append(conditions, "FirstCreatedAt > :CreatedAfter")
+		expressionAttributeValues[":CreatedAfter"] = &types.AttributeValueMemberS{Value: modelQuery.CreatedAfter.UTC().Format(time.RFC3339)}
+	}
+
+	if !modelQuery.CreatedBefore.IsZero() {
+		conditions = append(conditions, "FirstCreatedAt < :CreatedBefore")
+		expressionAttributeValues[":CreatedBefore"] = &types.AttributeValueMemberS{Value: modelQuery.CreatedBefore.UTC().Format(time.RFC3339)}
+	}
+
+	keys := make([]string, 0, len(modelQuery.Filters))
+	for key := range modelQuery.Filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	modelItemType := reflect.TypeOf(modelItem).Elem()
+	for idx, key := range keys {
+		attributeName, attributeValue, err := filterAttribute(modelItemType, key, modelQuery.Filters[key])
+		if err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("#Filter%d", idx)
+		placeholder := fmt.Sprintf(":Filter%d", idx)
+		conditions = append(conditions, fmt.Sprintf("%s = %s", name, placeholder))
+		expressionAttributeNames[name] = attributeName
+		expressionAttributeValues[placeholder] = attributeValue
+	}
+
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	if queryInput.FilterExpression != nil {
+		conditions = append([]string{*queryInput.FilterExpression}, conditions...)
+	}
+	queryInput.FilterExpression = aws.String(strings.Join(conditions, " AND "))
+
+	if len(expressionAttributeNames) > 0 {
+		if queryInput.ExpressionAttributeNames == nil {
+			queryInput.ExpressionAttributeNames = make(map[string]string)
+		}
+		for name, attributeName := range expressionAttributeNames {
+			queryInput.ExpressionAttributeNames[name] = attributeName
+		}
+	}
+
+	for placeholder, attributeValue := range expressionAttributeValues {
+		queryInput.ExpressionAttributeValues[placeholder] = attributeValue
+	}
+
+	return nil
+}
+
+func matchesFilter(item map[string]types.AttributeValue, modelItem models.ModelItem, modelQuery *models.ModelQuery) (bool, error) {
+	_, isDeleted := item["DeletedAt"]
+	switch {
+	case modelQuery.Deleted == models.DELETED_FILTER_FALSE && isDeleted:
+		return false, nil
+	case modelQuery.Deleted == models.DELETED_FILTER_ONLY && !isDeleted:
+		return false, nil
+	}
+
+	createdAt := itemString(item, "FirstCreatedAt")
+	if !modelQuery.CreatedAfter.IsZero() && !(createdAt > modelQuery.CreatedAfter.UTC().Format(time.RFC3339)) {
+		return false, nil
+	}
+	if !modelQuery.CreatedBefore.IsZero() && !(createdAt < modelQuery.CreatedBefore.UTC().Format(time.RFC3339)) {
+		return false, nil
+	}
+
+	keys := make([]string, 0, len(modelQuery.Filters))
+	for key := range modelQuery.Filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	modelItemType := reflect.TypeOf(modelItem).Elem()
+	for _, key := range keys {
+		attributeName, attributeValue, err := filterAttribute(modelItemType, key, modelQuery.Filters[key])
+		if err != nil {
+			return false, err
+		}
+
+		switch typedAttributeValue := attributeValue.(type) {
+		case *types.AttributeValueMemberS:
+			itemAttributeValue, ok := item[attributeName].(*types.AttributeValueMemberS)
+			if !ok || itemAttributeValue.Value != typedAttributeValue.Value {
+				return false, nil
+			}
+		case *types.AttributeValueMemberN:
+			itemAttributeValue, ok := item[attributeName].(*types.AttributeValueMemberN)
+			if !ok {
+				return false, nil
+			}
+			itemNumber, err := strconv.ParseFloat(itemAttributeValue.Value, 64)
+			if err != nil {
+				return false, err
+			}
+			filterNumber, err := strconv.ParseFloat(typedAttributeValue.Value, 64)
+			if err != nil {
+				return false, err
+			}
+			if itemNumber != filterNumber {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+func validateFilters(modelItem models.ModelItem, modelQuery *models.ModelQuery) error {
+	modelItemType := reflect.TypeOf(modelItem).Elem()
+	for key, value := range modelQuery.Filters {
+		_, _, err := filterAttribute(modelItemType, key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func filterAttribute(modelItemType reflect.Type, key string, value string) (string, types.AttributeValue, error) {
+	structField, ok := modelItemType.FieldByNameFunc(func(name string) bool {
+		return strings.EqualFold(name, key) && !unfilterableAttributes[name]
+	})
+	if !ok {
+		return "", nil, models.NewModelError(models.ErrValidation, fmt.Sprintf("unsupported filter %s", key))
+	}
+
+	switch structField.Type.Kind() {
+	case reflect.String:
+		return structField.Name, &types.AttributeValueMemberS{Value: value}, nil
+	case reflect.Float64, reflect.Int:
+		_, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", nil, models.NewModelError(models.ErrValidation, fmt.Sprintf("invalid filter %s", key))
+		}
+		return structField.Name, &types.AttributeValueMemberN{Value: value}, nil
+	default:
+		return "", nil, models.NewModelError(models.ErrValidation, fmt.Sprintf("unsupported filter %s", key))
+	}
+}
@@ -0,0 +1,502 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"j-and-a/internal/models"
+)
+
+func NewMemoryTable(cursorSigningKey []byte) *MemoryTable {
+	return &MemoryTable{
+		CursorSigningKey: cursorSigningKey,
+		items:            make(map[string]map[string]map[string]types.AttributeValue),
+	}
+}
+
+type MemoryTable struct {
+	CursorSigningKey []byte
+	mutex            sync.Mutex
+	items            map[string]map[string]map[string]types.AttributeValue
+}
+
+func (t *MemoryTable) BackfillRootItems(ctx context.Context) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	rootItems := t.query(func(item map[string]types.AttributeValue) bool {
+		return strings.HasPrefix(itemString(item, "SK"), models.SORT_KEY_VERSION_PREFIX+"0#") && needsBackfill(item)
+	}, "SK")
+
+	for _, rootItem := range rootItems {
+		_, sortType, sortId, err := models.DecodeSortKey(itemString(rootItem, "SK"))
+		if err != nil {
+			return 0, err
+		}
+
+		firstItem, _ := t.getItem(itemString(rootItem, "PK"), models.EncodeSortKey(1, sortType, sortId))
+		attributes, err := backfillAttributes(rootItem, firstItem)
+		if err != nil {
+			return 0, err
+		}
+
+		for name, attributeValue := range attributes {
+			rootItem[name] = attributeValue
+		}
+		t.putItem(rootItem)
+	}
+
+	return len(rootItems), nil
+}
+
+func (t *MemoryTable) DeleteByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	partitionKey := models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)
+	rootItem, ok := t.getItem(partitionKey, models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	if !ok {
+		return models.NewModelError(models.ErrNotFound, "item not found")
+	}
+
+	if _, isDeleted := rootItem["DeletedAt"]; isDeleted {
+		return models.NewModelError(models.ErrConflict, "item already deleted")
+	}
+
+	latestVersion, err := itemLatestVersion(rootItem)
+	if err != nil {
+		return err
+	}
+
+	deletedAt, deletedBy, err := requestedAtAndBy(ctx)
+	if err != nil {
+		return err
+	}
+
+	expectedVersion, hasExpectedVersion := ctx.Value("expectedVersion").(int)
+	if hasExpectedVersion && expectedVersion != latestVersion {
+		return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+	}
+
+	item, ok := t.getItem(partitionKey, models.EncodeSortKey(latestVersion, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	if _, isDeleted := item["DeletedAt"]; !ok || isDeleted {
+		if hasExpectedVersion {
+			return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+		}
+		return models.NewModelError(models.ErrConflict, "item was modified concurrently")
+	}
+
+	rootItem["DeletedAt"] = &types.AttributeValueMemberS{Value: deletedAt}
+	rootItem["DeletedBy"] = &types.AttributeValueMemberS{Value: deletedBy}
+	item["DeletedAt"] = &types.AttributeValueMemberS{Value: deletedAt}
+	item["DeletedBy"] = &types.AttributeValueMemberS{Value: deletedBy}
+	t.putItem(rootItem)
+	t.putItem(item)
+
+	return nil
+}
+
+func (t *MemoryTable) QueryItemsByPartitionId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	partitionKey := models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)
+	if !modelQuery.AsOf.IsZero() {
+		err := validateAsOf(modelQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		return pageAsOf(t.query(func(item map[string]types.AttributeValue) bool {
+			return itemString(item, "PK") == partitionKey &&
+				strings.HasPrefix(itemString(item, "SK"), models.SORT_KEY_VERSION_PREFIX) &&
+				itemString(item, "ModelType") == string(modelIdentifiers.SortType)
+		}, "SK"), itemCodec, modelQuery)
+	}
+
+	sortKeyPrefix := models.EncodeAnonymousSortKey(0, modelIdentifiers.SortType)
+	workDateLowerBound, workDateUpperBound := modelQuery.WorkDateBounds()
+	return t.queryPage(t.query(func(item map[string]types.AttributeValue) bool {
+		return itemString(item, "PK") == partitionKey &&
+			strings.HasPrefix(itemString(item, "SK"), sortKeyPrefix) &&
+			(!modelQuery.HasWorkDateRange() || (itemString(item, "WorkDate") >= workDateLowerBound && itemString(item, "WorkDate") < workDateUpperBound))
+	}, "SK"), itemCodec, modelQuery, "SK", cursorScope(modelQuery, "PK="+partitionKey, "SK="+sortKeyPrefix))
+}
+
+func (t *MemoryTable) GetItemByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (map[string]types.AttributeValue, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	item, ok := t.getItem(models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId), models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	if !ok {
+		return nil, models.NewModelError(models.ErrNotFound, "item not found")
+	}
+
+	return item, nil
+}
+
+func (t *MemoryTable) QueryItemsByPersonId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error) {
+	err := validatePersonIdQuery(modelQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	workDateLowerBound, workDateUpperBound := modelQuery.WorkDateBounds()
+	return t.queryPage(t.query(func(item map[string]types.AttributeValue) bool {
+		return itemString(item, "PersonId") == modelIdentifiers.PartitionId &&
+			itemString(item, "WorkDateKey") >= workDateLowerBound &&
+			itemString(item, "WorkDateKey") <= workDateUpperBound &&
+			itemString(item, "ModelType") == string(modelIdentifiers.SortType)
+	}, "WorkDateKey"), itemCodec, modelQuery, "WorkDateKey", cursorScope(modelQuery, "PersonId="+modelIdentifiers.PartitionId, "ModelType="+string(modelIdentifiers.SortType), "WorkDateKey"))
+}
+
+func (t *MemoryTable) QueryItemsBySortType(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !modelQuery.AsOf.IsZero() {
+		err := validateAsOf(modelQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		return pageAsOf(t.query(func(item map[string]types.AttributeValue) bool {
+			return itemString(item, "ModelType") == string(modelIdentifiers.SortType) &&
+				strings.HasPrefix(itemString(item, "SK"), models.SORT_KEY_VERSION_PREFIX)
+		}, "SK"), itemCodec, modelQuery)
+	}
+
+	if modelQuery.HasWorkDateRange() {
+		workDateLowerBound, workDateUpperBound := modelQuery.WorkDateBounds()
+		return t.queryPage(t.query(func(item map[string]types.AttributeValue) bool {
+			return itemString(item, "ModelType") == string(modelIdentifiers.SortType) &&
+				itemString(item, "WorkDateKey") >= workDateLowerBound &&
+				itemString(item, "WorkDateKey") <= workDateUpperBound
+		}, "WorkDateKey"), itemCodec, modelQuery, "WorkDateKey", cursorScope(modelQuery, "ModelType="+string(modelIdentifiers.SortType), "WorkDateKey"))
+	}
+
+	sortKeyPrefix := models.EncodeAnonymousSortKey(0, modelIdentifiers.SortType)
+	return t.queryPage(t.query(func(item map[string]types.AttributeValue) bool {
+		return itemString(item, "ModelType") == string(modelIdentifiers.SortType) &&
+			strings.HasPrefix(itemString(item, "SK"), sortKeyPrefix)
+	}, "SK"), itemCodec, modelQuery, "SK", cursorScope(modelQuery, "ModelType="+string(modelIdentifiers.SortType), "SK="+sortKeyPrefix))
+}
+
+func (t *MemoryTable) GetVersionItemByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (map[string]types.AttributeValue, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	item, ok := t.getItem(models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId), models.EncodeSortKey(modelIdentifiers.Version, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	if !ok {
+		return nil, models.NewModelError(models.ErrNotFound, "version not found")
+	}
+
+	return item, nil
+}
+
+func (t *MemoryTable) GetVersionItemsByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) ([]map[string]types.AttributeValue, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	partitionKey := models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)
+	rootItem, ok := t.getItem(partitionKey, models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	if !ok {
+		return nil, models.NewModelError(models.ErrNotFound, "item not found")
+	}
+
+	latestVersion, err := itemLatestVersion(rootItem)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]map[string]types.AttributeValue, 0, latestVersion)
+	for sortKeyVersion := latestVersion; sortKeyVersion > 0; sortKeyVersion-- {
+		item, ok := t.getItem(partitionKey, models.EncodeSortKey(sortKeyVersion, modelIdentifiers.SortType, modelIdentifiers.SortId))
+		if ok {
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+func (t *MemoryTable) PutByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelPayload models.ModelPayload) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.put(ctx, modelIdentifiers, modelPayload, false)
+}
+
+func (t *MemoryTable) put(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelPayload models.ModelPayload, requireUndeleted bool) error {
+	partitionKey := models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)
+	rootItem, hasRootItem := t.getItem(partitionKey, models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId))
+
+	latestVersion, err := itemLatestVersion(rootItem)
+	if err != nil {
+		return err
+	}
+
+	createdAt, createdBy, err := requestedAtAndBy(ctx)
+	if err != nil {
+		return err
+	}
+
+	expectedVersion, hasExpectedVersion := ctx.Value("expectedVersion").(int)
+	if hasExpectedVersion && expectedVersion != latestVersion {
+		return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+	}
+
+	_, isDeleted := rootItem["DeletedAt"]
+	if hasExpectedVersion && isDeleted {
+		return models.NewModelError(models.ErrPreconditionFailed, "item deleted")
+	}
+	if requireUndeleted && isDeleted {
+		return models.NewModelError(models.ErrConflict, "item deleted")
+	}
+
+	_, hasItem := t.getItem(partitionKey, models.EncodeSortKey(latestVersion+1, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	if (latestVersion == 0 && hasRootItem) || hasItem {
+		if hasExpectedVersion {
+			return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+		}
+		return models.NewModelError(models.ErrConflict, "item was modified concurrently")
+	}
+
+	previousRootItem := rootItem
+	rootItem, err = attributevalue.MarshalMap(modelPayload.Item(modelIdentifiers, 0, latestVersion+1, createdAt, createdBy))
+	if err != nil {
+		return err
+	}
+	setFirstCreatedAt(rootItem, previousRootItem, createdAt)
+
+	item, err := attributevalue.MarshalMap(modelPayload.Item(modelIdentifiers, latestVersion+1, 0, createdAt, createdBy))
+	if err != nil {
+		return err
+	}
+
+	t.putItem(rootItem)
+	t.putItem(item)
+
+	return nil
+}
+
+func (t *MemoryTable) RestoreByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	partitionKey := models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)
+	rootItem, ok := t.getItem(partitionKey, models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	if !ok {
+		return models.NewModelError(models.ErrNotFound, "item not found")
+	}
+
+	if _, ok := rootItem["DeletedAt"]; !ok {
+		return models.NewModelError(models.ErrConflict, "item not deleted")
+	}
+
+	latestVersion, err := itemLatestVersion(rootItem)
+	if err != nil {
+		return err
+	}
+
+	restoredAt, restoredBy, err := requestedAtAndBy(ctx)
+	if err != nil {
+		return err
+	}
+
+	expectedVersion, hasExpectedVersion := ctx.Value("expectedVersion").(int)
+	if hasExpectedVersion && expectedVersion != latestVersion {
+		return models.NewModelError(models.ErrPreconditionFailed, "item version does not match if match")
+	}
+
+	_, hasItem := t.getItem(partitionKey, models.EncodeSortKey(latestVersion+1, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	if hasItem {
+		return models.NewModelError(models.ErrConflict, "item was modified concurrently")
+	}
+
+	delete(rootItem, "DeletedAt")
+	delete(rootItem, "DeletedBy")
+	rootItem["RestoredAt"] = &types.AttributeValueMemberS{Value: restoredAt}
+	rootItem["RestoredBy"] = &types.AttributeValueMemberS{Value: restoredBy}
+
+	item := copyItem(rootItem)
+	delete(item, "LatestVersion")
+	delete(item, "WorkDateKey")
+	delete(item, "FirstCreatedAt")
+	item["SK"] = &types.AttributeValueMemberS{Value: models.EncodeSortKey(latestVersion+1, modelIdentifiers.SortType, modelIdentifiers.SortId)}
+
+	rootItem["LatestVersion"], err = attributevalue.Marshal(latestVersion + 1)
+	if err != nil {
+		return err
+	}
+
+	t.putItem(rootItem)
+	t.putItem(item)
+
+	return nil
+}
+
+func (t *MemoryTable) RevertItemByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	partitionKey := models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)
+	rootItem, _ := t.getItem(partitionKey, models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	item, ok := t.getItem(partitionKey, models.EncodeSortKey(modelIdentifiers.Version, modelIdentifiers.SortType, modelIdentifiers.SortId))
+	if _, isDeleted := rootItem["DeletedAt"]; isDeleted {
+		return models.NewModelError(models.ErrConflict, "item deleted")
+	}
+	if !ok {
+		return models.NewModelError(models.ErrNotFound, "version not found")
+	}
+
+	modelItem := itemCodec.NewModelItem()
+	err := attributevalue.UnmarshalMap(item, modelItem)
+	if err != nil {
+		return err
+	}
+
+	modelPayload := modelItem.Payload()
+	err = modelPayload.Validate()
+	if err != nil {
+		return err
+	}
+
+	return t.put(ctx, modelIdentifiers, modelPayload, true)
+}
+
+func (t *MemoryTable) getItem(partitionKey string, sortKey string) (map[string]types.AttributeValue, bool) {
+	item, ok := t.items[partitionKey][sortKey]
+	if !ok {
+		return nil, false
+	}
+	return copyItem(item), true
+}
+
+func (t *MemoryTable) putItem(item map[string]types.AttributeValue) {
+	partitionKey := itemString(item, "PK")
+	if t.items[partitionKey] == nil {
+		t.items[partitionKey] = make(map[string]map[string]types.AttributeValue)
+	}
+	t.items[partitionKey][itemString(item, "SK")] = copyItem(item)
+}
+
+func (t *MemoryTable) query(match func(item map[string]types.AttributeValue) bool, rangeKeyName string) []map[string]types.AttributeValue {
+	items := make([]map[string]types.AttributeValue, 0)
+	for _, sortKeyItems := range t.items {
+		for _, item := range sortKeyItems {
+			if match(item) {
+				items = append(items, copyItem(item))
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return keyLess(items[i], items[j], rangeKeyName)
+	})
+
+	return items
+}
+
+func (t *MemoryTable) queryPage(items []map[string]types.AttributeValue, itemCodec ItemCodec, modelQuery *models.ModelQuery, rangeKeyName string, scope string) (*ItemPage, error) {
+	modelItem := itemCodec.NewModelItem()
+	err := validateFilters(modelItem, modelQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	filteredItems := make([]map[string]types.AttributeValue, 0, len(items))
+	for _, item := range items {
+		ok, err := matchesFilter(item, modelItem, modelQuery)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+
+	if len(modelQuery.Sort) > 0 {
+		return pageSorted(filteredItems, itemCodec, modelQuery, scope, t.CursorSigningKey)
+	}
+
+	exclusiveStartKey, err := DecodeCursor(modelQuery.Cursor, scope, t.CursorSigningKey)
+	if err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if exclusiveStartKey != nil {
+		start = sort.Search(len(filteredItems), func(idx int) bool {
+			return keyLess(exclusiveStartKey, filteredItems[idx], rangeKeyName)
+		})
+	}
+
+	end := len(filteredItems)
+	if modelQuery.Limit > 0 {
+		end = min(start+modelQuery.Limit, len(filteredItems))
+	}
+
+	nextCursor := ""
+	if modelQuery.Limit > 0 && end-start == modelQuery.Limit {
+		nextCursor, err = EncodeCursor(map[string]types.AttributeValue{
+			"PK":         filteredItems[end-1]["PK"],
+			"SK":         filteredItems[end-1]["SK"],
+			rangeKeyName: filteredItems[end-1][rangeKeyName],
+		}, scope, t.CursorSigningKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ItemPage{Items: filteredItems[start:end], NextCursor: nextCursor}, nil
+}
+
+func keyLess(leftItem map[string]types.AttributeValue, rightItem map[string]types.AttributeValue, rangeKeyName string) bool {
+	for _, name := range []string{rangeKeyName, "SK", "PK"} {
+		if itemString(leftItem, name) != itemString(rightItem, name) {
+			return itemString(leftItem, name) < itemString(rightItem, name)
+		}
+	}
+	return false
+}
+
+func itemString(item map[string]types.AttributeValue, name string) string {
+	attributeValue, ok := item[name].(*types.AttributeValueMemberS)
+	if !ok {
+		return ""
+	}
+	return attributeValue.Value
+}
+
+func itemLatestVersion(item map[string]types.AttributeValue) (int, error) {
+	latestVersion := 0
+	if latestVersionAttributeValue, ok := item["LatestVersion"]; ok {
+		err := attributevalue.Unmarshal(latestVersionAttributeValue, &latestVersion)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return latestVersion, nil
+}
+
+func requestedAtAndBy(ctx context.Context) (string, string, error) {
+	unixMilli, ok := ctx.Value("requestedAt").(int64)
+	if !ok {
+		return "", "", models.NewModelError(models.ErrInternal, "failed to parse requested at within context")
+	}
+	requestedBy, ok := ctx.Value("requestedBy").(string)
+	if !ok {
+		return "", "", models.NewModelError(models.ErrForbidden, "missing requested by within context")
+	}
+	return time.UnixMilli(unixMilli).Format(time.RFC3339), requestedBy, nil
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"j-and-a/internal/models"
+)
+
+const SORT_ITEM_LIMIT = 1000
+
+func pageSorted(items []map[string]types.AttributeValue, itemCodec ItemCodec, modelQuery *models.ModelQuery, scope string, cursorSigningKey []byte) (*ItemPage, error) {
+	offset, err := DecodeOffsetCursor(modelQuery.Cursor, scope, cursorSigningKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) > SORT_ITEM_LIMIT {
+		return nil, models.NewModelError(models.ErrValidation, fmt.Sprintf("sort is limited to %d items, narrow the filters", SORT_ITEM_LIMIT))
+	}
+
+	err = itemCodec.SortItems(items, modelQuery.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	start := min(offset, len(items))
+	end := len(items)
+	if modelQuery.Limit > 0 {
+		end = min(start+modelQuery.Limit, len(items))
+	}
+
+	nextCursor := ""
+	if end < len(items) {
+		nextCursor, err = EncodeOffsetCursor(end, scope, cursorSigningKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ItemPage{Items: items[start:end], NextCursor: nextCursor}, nil
+}
+
+func validateAsOf(modelQuery *models.ModelQuery) error {
+	if modelQuery.Limit > 0 || modelQuery.Cursor != "" {
+		return models.NewModelError(models.ErrValidation, "pagination is not supported with as of")
+	}
+
+	if modelQuery.IsFiltered() {
+		return models.NewModelError(models.ErrValidation, "filters are not supported with as of")
+	}
+
+	return nil
+}
+
+func validatePersonIdQuery(modelQuery *models.ModelQuery) error {
+	if !modelQuery.AsOf.IsZero() {
+		return models.NewModelError(models.ErrValidation, "as of is not supported by person")
+	}
+
+	for key := range modelQuery.Filters {
+		if strings.EqualFold(key, "PersonId") {
+			return models.NewModelError(models.ErrValidation, fmt.Sprintf("unsupported filter %s", key))
+		}
+	}
+
+	return nil
+}
+
+func pageAsOf(items []map[string]types.AttributeValue, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error) {
+	asOf := modelQuery.AsOf
+	latestVersions := make(map[string]int)
+	latestItems := make(map[string]map[string]types.AttributeValue)
+	for _, item := range items {
+		auditItem := new(struct {
+			PK        string
+			SK        string
+			CreatedAt string
+		})
+		err := attributevalue.UnmarshalMap(item, auditItem)
+		if err != nil {
+			return nil, err
+		}
+
+		version, _, sortId, err := models.DecodeSortKey(auditItem.SK)
+		if err != nil {
+			return nil, err
+		}
+		if version == 0 {
+			continue
+		}
+
+		createdAt, err := time.Parse(time.RFC3339, auditItem.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if createdAt.After(asOf) {
+			continue
+		}
+
+		key := auditItem.PK + "#" + sortId
+		if version > latestVersions[key] {
+			latestVersions[key] = version
+			latestItems[key] = item
+		}
+	}
+
+	asOfItems := make([]map[string]types.AttributeValue, 0, len(latestItems))
+	for _, latestItem := range latestItems {
+		if deletedAtAttributeValue, ok := latestItem["DeletedAt"]; ok {
+			var deletedAtString string
+			err := attributevalue.Unmarshal(deletedAtAttributeValue, &deletedAtString)
+			if err != nil {
+				return nil, err
+			}
+
+			deletedAt, err := time.Parse(time.RFC3339, deletedAtString)
+			if err != nil {
+				return nil, err
+			}
+			if deletedAt.After(asOf) {
+				latestItem = copyItem(latestItem)
+				delete(latestItem, "DeletedAt")
+				delete(latestItem, "DeletedBy")
+			}
+		}
+
+		asOfItems = append(asOfItems, latestItem)
+	}
+
+	err := itemCodec.SortItems(asOfItems, modelQuery.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ItemPage{Items: asOfItems}, nil
+}
+
+func setFirstCreatedAt(rootItem map[string]types.AttributeValue, previousRootItem map[string]types.AttributeValue, createdAt string) {
+	if firstCreatedAt, ok := previousRootItem["FirstCreatedAt"]; ok {
+		rootItem["FirstCreatedAt"] = firstCreatedAt
+	} else if len(previousRootItem) == 0 {
+		rootItem["FirstCreatedAt"] = &types.AttributeValueMemberS{Value: createdAt}
+	}
+}
+
+func unmarshalData[D models.ModelData](item map[string]types.AttributeValue, newItem func() models.TypedModelItem[D]) (D, error) {
+	modelItem := newItem()
+	err := attributevalue.UnmarshalMap(item, modelItem)
+	if err != nil {
+		var data D
+		return data, err
+	}
+
+	return modelItem.TypedData()
+}
+
+func unmarshalDatas[D models.ModelData](items []map[string]types.AttributeValue, newItem func() models.TypedModelItem[D]) ([]D, error) {
+	datas := make([]D, 0, len(items))
+	for _, item := range items {
+		data, err := unmarshalData(item, newItem)
+		if err != nil {
+			return nil, err
+		}
+		datas = append(datas, data)
+	}
+	return datas, nil
+}
+
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	copiedItem := make(map[string]types.AttributeValue, len(item))
+	for key, attributeValue := range item {
+		copiedItem[key] = attributeValue
+	}
+	return copiedItem
+}
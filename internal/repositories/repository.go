@@ -2,239 +2,178 @@ package repositories
 
 import (
 	"context"
-	"errors"
-	"time"
 
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/aws/aws-sdk-go/aws"
 
 	"j-and-a/internal/models"
 )
 
-type Repository struct {
-	Client    *dynamodb.Client
-	TableName string
-	IndexName string
-}
-
-func (r *Repository) DeleteByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
-	getItemOutput, err := r.Client.GetItem(ctx, &dynamodb.GetItemInput{
-		TableName: aws.String(r.TableName),
-		Key: map[string]types.AttributeValue{
-			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
-			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
-		},
-		ProjectionExpression: aws.String("LatestVersion"),
-	})
-	if err != nil {
-		return err
-	}
-
-	latestVersion := 0
-	if lastedVersionAttributeValue, ok := getItemOutput.Item["LatestVersion"]; ok {
-		err = attributevalue.Unmarshal(lastedVersionAttributeValue, &latestVersion)
-		if err != nil {
-			return err
-		}
-	}
-
-	unixMilli, ok := ctx.Value("requestedAt").(int64)
-	if !ok {
-		return errors.New("failed to parse requested at within context")
-	}
-	deletedAt := time.UnixMilli(unixMilli).Format(time.RFC3339)
-	deletedBy, ok := ctx.Value("requestedBy").(string)
-	if !ok {
-		return errors.New("missing requested by within context")
-	}
+type ItemPage struct {
+	Items      []map[string]types.AttributeValue
+	NextCursor string
+}
 
-	_, err = r.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
-		TransactItems: []types.TransactWriteItem{
-			{Update: &types.Update{
-				TableName: &r.TableName,
-				Key: map[string]types.AttributeValue{
-					"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
-					"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
-				},
-				UpdateExpression: aws.String("SET DeletedAt = :DeletedAt, DeletedBy = :DeletedBy"),
-				ExpressionAttributeValues: map[string]types.AttributeValue{
-					":DeletedAt": &types.AttributeValueMemberS{Value: deletedAt},
-					":DeletedBy": &types.AttributeValueMemberS{Value: deletedBy},
-				},
-				ConditionExpression: aws.String("attribute_not_exists(deletedAt)"),
-			}},
-			{Update: &types.Update{
-				TableName: &r.TableName,
-				Key: map[string]types.AttributeValue{
-					"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
-					"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(latestVersion, modelIdentifiers.SortType, modelIdentifiers.SortId)},
-				},
-				UpdateExpression: aws.String("SET DeletedAt = :DeletedAt, DeletedBy = :DeletedBy"),
-				ExpressionAttributeValues: map[string]types.AttributeValue{
-					":DeletedAt": &types.AttributeValueMemberS{Value: deletedAt},
-					":DeletedBy": &types.AttributeValueMemberS{Value: deletedBy},
-				},
-				ConditionExpression: aws.String("attribute_not_exists(deletedAt)"),
-			}},
-		},
-	})
-
-	return err
-}
-
-func (r *Repository) GetByPartitionId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelItem models.ModelItem) ([]models.ModelData, error) {
-	queryOutput, err := r.Client.Query(ctx, &dynamodb.QueryInput{
-		TableName:              aws.String(r.TableName),
-		KeyConditionExpression: aws.String("PK = :PK AND begins_with(SK, :SK)"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
-			":SK": &types.AttributeValueMemberS{Value: models.EncodeAnonymousSortKey(0, modelIdentifiers.SortType)},
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
+type ItemCodec interface {
+	NewModelItem() models.ModelItem
+	SortItems(items []map[string]types.AttributeValue, sortFields []models.SortField) error
+}
 
-	datas := make([]models.ModelData, queryOutput.Count)
-	for idx, queryOutputItem := range queryOutput.Items {
-		modelItem = modelItem.New()
-		err = attributevalue.UnmarshalMap(queryOutputItem, modelItem)
-		if err != nil {
-			return nil, err
-		}
+type Table interface {
+	BackfillRootItems(ctx context.Context) (int, error)
+	DeleteByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error
+	GetItemByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (map[string]types.AttributeValue, error)
+	GetVersionItemByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (map[string]types.AttributeValue, error)
+	GetVersionItemsByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) ([]map[string]types.AttributeValue, error)
+	PutByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelPayload models.ModelPayload) error
+	QueryItemsByPartitionId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error)
+	QueryItemsByPersonId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error)
+	QueryItemsBySortType(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error)
+	RestoreByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error
+	RevertItemByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec) error
+}
 
-		data, err := modelItem.Data()
-		if err != nil {
-			return nil, err
-		}
+type Repository[D models.TypedModelData[D]] interface {
+	DeleteByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error
+	GetByPartitionId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error)
+	GetByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (D, error)
+	GetByPersonId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error)
+	GetBySortType(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error)
+	GetDiffByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, fromVersion int, toVersion int) ([]models.ModelDiff, error)
+	GetVersionByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (D, error)
+	GetVersionsByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) ([]D, error)
+	PutByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelPayload models.ModelPayload) error
+	RestoreByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error
+	RevertByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error
+}
 
-		datas[idx] = data
-	}
+func NewRepository[D models.TypedModelData[D]](table Table, newItem func() models.TypedModelItem[D]) Repository[D] {
+	return &TableRepository[D]{Table: table, NewItem: newItem}
+}
 
-	OrderedBy(isDeleted, updatedAt).Sort(datas)
+type TableRepository[D models.TypedModelData[D]] struct {
+	Table   Table
+	NewItem func() models.TypedModelItem[D]
+}
 
-	return datas, nil
+func (r *TableRepository[D]) DeleteByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+	return r.Table.DeleteByPartitionIdAndSortId(ctx, modelIdentifiers)
 }
 
-func (r *Repository) GetByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelItem models.ModelItem) (models.ModelData, error) {
-	getItemOutput, err := r.Client.GetItem(ctx, &dynamodb.GetItemInput{
-		TableName: aws.String(r.TableName),
-		Key: map[string]types.AttributeValue{
-			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
-			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
-		},
-	})
+func (r *TableRepository[D]) GetByPartitionId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error) {
+	itemPage, err := r.Table.QueryItemsByPartitionId(ctx, modelIdentifiers, r, modelQuery)
 	if err != nil {
 		return nil, err
 	}
+	return r.unmarshalPage(itemPage, modelQuery)
+}
 
-	if getItemOutput.Item == nil {
-		return nil, errors.New("item not found")
+func (r *TableRepository[D]) GetByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (D, error) {
+	item, err := r.Table.GetItemByPartitionIdAndSortId(ctx, modelIdentifiers)
+	if err != nil {
+		var data D
+		return data, err
 	}
+	return unmarshalData(item, r.NewItem)
+}
 
-	err = attributevalue.UnmarshalMap(getItemOutput.Item, modelItem)
+func (r *TableRepository[D]) GetByPersonId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error) {
+	itemPage, err := r.Table.QueryItemsByPersonId(ctx, modelIdentifiers, r, modelQuery)
 	if err != nil {
 		return nil, err
 	}
+	return r.unmarshalPage(itemPage, modelQuery)
+}
 
-	data, err := modelItem.Data()
+func (r *TableRepository[D]) GetBySortType(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error) {
+	itemPage, err := r.Table.QueryItemsBySortType(ctx, modelIdentifiers, r, modelQuery)
 	if err != nil {
 		return nil, err
 	}
-
-	return data, nil
+	return r.unmarshalPage(itemPage, modelQuery)
 }
 
-func (r *Repository) GetBySortType(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelItem models.ModelItem) ([]models.ModelData, error) {
-	queryOutput, err := r.Client.Query(ctx, &dynamodb.QueryInput{
-		TableName:              aws.String(r.TableName),
-		IndexName:              aws.String(r.IndexName),
-		KeyConditionExpression: aws.String("ModelType = :ModelType AND begins_with(SK, :SK)"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":ModelType": &types.AttributeValueMemberS{Value: string(modelIdentifiers.SortType)},
-			":SK":        &types.AttributeValueMemberS{Value: models.EncodeAnonymousSortKey(0, modelIdentifiers.SortType)},
-		},
-	})
+func (r *TableRepository[D]) GetDiffByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, fromVersion int, toVersion int) ([]models.ModelDiff, error) {
+	fromModelIdentifiers := *modelIdentifiers
+	fromModelIdentifiers.Version = fromVersion
+	fromData, err := r.GetVersionByPartitionIdAndSortId(ctx, &fromModelIdentifiers)
 	if err != nil {
 		return nil, err
 	}
 
-	datas := make([]models.ModelData, queryOutput.Count)
-	for idx, queryOutputItem := range queryOutput.Items {
-		modelItem = modelItem.New()
-		err = attributevalue.UnmarshalMap(queryOutputItem, modelItem)
-		if err != nil {
-			return nil, err
-		}
-
-		data, err := modelItem.Data()
-		if err != nil {
-			return nil, err
-		}
-
-		datas[idx] = data
+	toModelIdentifiers := *modelIdentifiers
+	toModelIdentifiers.Version = toVersion
+	toData, err := r.GetVersionByPartitionIdAndSortId(ctx, &toModelIdentifiers)
+	if err != nil {
+		return nil, err
 	}
 
-	OrderedBy(isDeleted, updatedAt).Sort(datas)
-
-	return datas, nil
+	return models.DiffData(fromData, toData)
 }
 
-func (r *Repository) PutByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelPayload models.ModelPayload) error {
-	getItemOutput, err := r.Client.GetItem(ctx, &dynamodb.GetItemInput{
-		TableName: aws.String(r.TableName),
-		Key: map[string]types.AttributeValue{
-			"PK": &types.AttributeValueMemberS{Value: models.EncodePartitionKey(modelIdentifiers.PartitionType, modelIdentifiers.PartitionId)},
-			"SK": &types.AttributeValueMemberS{Value: models.EncodeSortKey(0, modelIdentifiers.SortType, modelIdentifiers.SortId)},
-		},
-		ProjectionExpression: aws.String("LatestVersion"),
-	})
+func (r *TableRepository[D]) GetVersionByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) (D, error) {
+	item, err := r.Table.GetVersionItemByPartitionIdAndSortId(ctx, modelIdentifiers)
 	if err != nil {
-		return err
+		var data D
+		return data, err
 	}
+	return unmarshalData(item, r.NewItem)
+}
 
-	latestVersion := 0
-	if lastedVersionAttributeValue, ok := getItemOutput.Item["LatestVersion"]; ok {
-		err = attributevalue.Unmarshal(lastedVersionAttributeValue, &latestVersion)
-		if err != nil {
-			return err
-		}
+func (r *TableRepository[D]) GetVersionsByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) ([]D, error) {
+	items, err := r.Table.GetVersionItemsByPartitionIdAndSortId(ctx, modelIdentifiers)
+	if err != nil {
+		return nil, err
 	}
 
-	unixMilli, ok := ctx.Value("requestedAt").(int64)
-	if !ok {
-		return errors.New("failed to parse requested at within context")
+	datas, err := unmarshalDatas(items, r.NewItem)
+	if err != nil {
+		return nil, err
 	}
-	createdAt := time.UnixMilli(unixMilli).Format(time.RFC3339)
-	createdBy, ok := ctx.Value("requestedBy").(string)
-	if !ok {
-		return errors.New("failed to parse requested by within context")
+
+	err = OrderedBy(version[D]).Sort(datas)
+	if err != nil {
+		return nil, err
 	}
 
-	rootItem, err := attributevalue.MarshalMap(modelPayload.Item(modelIdentifiers, 0, latestVersion+1, createdAt, createdBy))
+	return datas, nil
+}
+
+func (r *TableRepository[D]) PutByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, modelPayload models.ModelPayload) error {
+	return r.Table.PutByPartitionIdAndSortId(ctx, modelIdentifiers, modelPayload)
+}
+
+func (r *TableRepository[D]) RestoreByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+	return r.Table.RestoreByPartitionIdAndSortId(ctx, modelIdentifiers)
+}
+
+func (r *TableRepository[D]) RevertByPartitionIdAndSortId(ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+	return r.Table.RevertItemByPartitionIdAndSortId(ctx, modelIdentifiers, r)
+}
+
+func (r *TableRepository[D]) NewModelItem() models.ModelItem {
+	return r.NewItem()
+}
+
+func (r *TableRepository[D]) SortItems(items []map[string]types.AttributeValue, sortFields []models.SortField) error {
+	datas, err := unmarshalDatas(items, r.NewItem)
 	if err != nil {
 		return err
 	}
+	return OrderedBy(sortLesses[D](sortFields)...).SortItems(datas, items)
+}
 
-	item, err := attributevalue.MarshalMap(modelPayload.Item(modelIdentifiers, latestVersion+1, 0, createdAt, createdBy))
+func (r *TableRepository[D]) unmarshalPage(itemPage *ItemPage, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error) {
+	datas, err := unmarshalDatas(itemPage.Items, r.NewItem)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if len(modelQuery.Sort) == 0 {
+		err = OrderedBy(sortLesses[D](nil)...).Sort(datas)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	_, err = r.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
-		TransactItems: []types.TransactWriteItem{
-			{Put: &types.Put{
-				TableName: &r.TableName,
-				Item:      rootItem,
-			}},
-			{Put: &types.Put{
-				TableName: &r.TableName,
-				Item:      item,
-			}},
-		},
-	})
-
-	return err
+	return &models.TypedModelPage[D]{Items: datas, NextCursor: itemPage.NextCursor}, nil
 }
@@ -0,0 +1,817 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+
+	"j-and-a/internal/models"
+)
+
+const (
+	TEST_CURSOR_SIGNING_KEY = "test-cursor-signing-key"
+	TEST_REQUESTED_AT       = int64(1737331200000)
+	TEST_REQUESTED_BY       = "test-user"
+	TEST_PERSON_ID          = "019491b4-4d1f-7df2-be95-62e0e684353f"
+	TEST_OTHER_PERSON_ID    = "019491b4-4d1f-7df2-be95-62e0e684353a"
+)
+
+type testTable struct {
+	name     string
+	newTable func(t *testing.T) Table
+}
+
+func testTables() []testTable {
+	return []testTable{
+		{name: "memory", newTable: func(t *testing.T) Table {
+			return NewMemoryTable([]byte(TEST_CURSOR_SIGNING_KEY))
+		}},
+		{name: "dynamodb", newTable: newDynamoDBTestTable},
+	}
+}
+
+func newDynamoDBTestTable(t *testing.T) Table {
+	if os.Getenv("AWS_ENDPOINT_URL_DYNAMODB") == "" {
+		t.Skip("AWS_ENDPOINT_URL_DYNAMODB is not set")
+	}
+
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := dynamodb.NewFromConfig(cfg)
+
+	table := &DynamoDBTable{
+		Client:            client,
+		TableName:         fmt.Sprintf("test-%d", time.Now().UnixNano()),
+		IndexName:         "ModelType-SK-index",
+		PersonIndexName:   "PersonId-WorkDateKey-index",
+		WorkDateIndexName: "ModelType-WorkDateKey-index",
+		CursorSigningKey:  []byte(TEST_CURSOR_SIGNING_KEY),
+	}
+
+	globalSecondaryIndex := func(indexName string, hashKey string, rangeKey string) types.GlobalSecondaryIndex {
+		return types.GlobalSecondaryIndex{
+			IndexName: aws.String(indexName),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String(hashKey), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String(rangeKey), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}
+	}
+
+	_, err = client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:   aws.String(table.TableName),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("PK"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("SK"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("ModelType"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("PersonId"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("WorkDateKey"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("PK"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("SK"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			globalSecondaryIndex(table.IndexName, "ModelType", "SK"),
+			globalSecondaryIndex(table.PersonIndexName, "PersonId", "WorkDateKey"),
+			globalSecondaryIndex(table.WorkDateIndexName, "ModelType", "WorkDateKey"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.DeleteTable(context.Background(), &dynamodb.DeleteTableInput{TableName: aws.String(table.TableName)})
+	})
+
+	err = dynamodb.NewTableExistsWaiter(client).Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table.TableName)}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return table
+}
+
+func testContext(step int) context.Context {
+	ctx := context.WithValue(context.Background(), "requestedAt", TEST_REQUESTED_AT+int64(step)*time.Second.Milliseconds())
+	return context.WithValue(ctx, "requestedBy", TEST_REQUESTED_BY)
+}
+
+func expectedVersionContext(step int, expectedVersion int) context.Context {
+	return context.WithValue(testContext(step), "expectedVersion", expectedVersion)
+}
+
+func logIdentifiers(jobId string, logId string) *models.ModelIdentifiers {
+	return &models.ModelIdentifiers{PartitionType: models.ModelTypeJob, PartitionId: jobId, SortType: models.ModelTypeLog, SortId: logId}
+}
+
+func jobMetadataIdentifiers(jobId string) *models.ModelIdentifiers {
+	return &models.ModelIdentifiers{PartitionType: models.ModelTypeJob, PartitionId: jobId, SortType: models.ModelTypeJobMetadata, SortId: jobId}
+}
+
+func logPayload(personId string, workDate string, hours float64) *models.LogPayload {
+	return &models.LogPayload{PersonId: personId, WorkDate: workDate, Hours: hours}
+}
+
+func newLogRepository(table Table) Repository[*models.LogData] {
+	return NewRepository(table, func() models.TypedModelItem[*models.LogData] { return new(models.LogItem) })
+}
+
+func newJobMetadataRepository(table Table) Repository[*models.JobMetadataData] {
+	return NewRepository(table, func() models.TypedModelItem[*models.JobMetadataData] { return new(models.JobMetadataItem) })
+}
+
+func putLogs(t *testing.T, table Table) {
+	for step, log := range []struct {
+		jobId   string
+		logId   string
+		payload *models.LogPayload
+	}{
+		{jobId: "j1", logId: "l1", payload: logPayload(TEST_PERSON_ID, "2025-01-20", 2)},
+		{jobId: "j1", logId: "l2", payload: logPayload(TEST_OTHER_PERSON_ID, "2025-01-21", 3)},
+		{jobId: "j1", logId: "l3", payload: logPayload(TEST_PERSON_ID, "2025-01-22", 4)},
+		{jobId: "j2", logId: "l4", payload: logPayload(TEST_PERSON_ID, "2025-01-29", 5)},
+		{jobId: "j2", logId: "l5", payload: logPayload(TEST_OTHER_PERSON_ID, "2025-01-19", 6)},
+	} {
+		err := table.PutByPartitionIdAndSortId(testContext(step), logIdentifiers(log.jobId, log.logId), log.payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := table.DeleteByPartitionIdAndSortId(testContext(10), logIdentifiers("j1", "l3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertErrorKind(t *testing.T, err error, kind error) {
+	t.Helper()
+	if kind == nil {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if !errors.Is(err, kind) {
+		t.Fatalf("expected %v error, got %v", kind, err)
+	}
+}
+
+func logIds(logDatas []*models.LogData) []string {
+	ids := make([]string, 0, len(logDatas))
+	for _, logData := range logDatas {
+		ids = append(ids, logData.LogId)
+	}
+	return ids
+}
+
+func assertLogIds(t *testing.T, logDatas []*models.LogData, expectedIds []string) {
+	t.Helper()
+	ids := logIds(logDatas)
+	if fmt.Sprint(ids) != fmt.Sprint(expectedIds) {
+		t.Fatalf("expected logs %v, got %v", expectedIds, ids)
+	}
+}
+
+func TestPutByPartitionIdAndSortId(t *testing.T) {
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		expectedKind    error
+		expectedVersion int
+		expectedHours   []float64
+	}{
+		{name: "creates next version", ctx: testContext(1), expectedVersion: 2, expectedHours: []float64{4, 2}},
+		{name: "matching expected version", ctx: expectedVersionContext(1, 1), expectedVersion: 2, expectedHours: []float64{4, 2}},
+		{name: "stale expected version", ctx: expectedVersionContext(1, 2), expectedKind: models.ErrPreconditionFailed, expectedVersion: 1, expectedHours: []float64{2}},
+		{name: "missing requested by", ctx: context.WithValue(context.Background(), "requestedAt", TEST_REQUESTED_AT), expectedKind: models.ErrForbidden, expectedVersion: 1, expectedHours: []float64{2}},
+	}
+
+	for _, testTable := range testTables() {
+		for _, test := range tests {
+			t.Run(testTable.name+"/"+test.name, func(t *testing.T) {
+				table := testTable.newTable(t)
+				repository := newLogRepository(table)
+				modelIdentifiers := logIdentifiers("j1", "l1")
+
+				err := table.PutByPartitionIdAndSortId(testContext(0), modelIdentifiers, logPayload(TEST_PERSON_ID, "2025-01-20", 2))
+				assertErrorKind(t, err, nil)
+
+				err = table.PutByPartitionIdAndSortId(test.ctx, modelIdentifiers, logPayload(TEST_PERSON_ID, "2025-01-20", 4))
+				assertErrorKind(t, err, test.expectedKind)
+
+				logData, err := repository.GetByPartitionIdAndSortId(testContext(2), modelIdentifiers)
+				assertErrorKind(t, err, nil)
+				if logData.Version != test.expectedVersion {
+					t.Fatalf("expected version %d, got %d", test.expectedVersion, logData.Version)
+				}
+
+				logDatas, err := repository.GetVersionsByPartitionIdAndSortId(testContext(2), modelIdentifiers)
+				assertErrorKind(t, err, nil)
+				hours := make([]float64, 0, len(logDatas))
+				for _, logData := range logDatas {
+					hours = append(hours, logData.Hours)
+				}
+				if fmt.Sprint(hours) != fmt.Sprint(test.expectedHours) {
+					t.Fatalf("expected version hours %v, got %v", test.expectedHours, hours)
+				}
+			})
+		}
+	}
+}
+
+func TestDeleteAndRestoreByPartitionIdAndSortId(t *testing.T) {
+	tests := []struct {
+		name            string
+		steps           []func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error
+		expectedKind    error
+		expectedDeleted bool
+		expectedVersion int
+	}{
+		{
+			name: "deletes",
+			steps: []func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error{
+				Table.DeleteByPartitionIdAndSortId,
+			},
+			expectedDeleted: true,
+			expectedVersion: 1,
+		},
+		{
+			name: "deletes twice",
+			steps: []func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error{
+				Table.DeleteByPartitionIdAndSortId,
+				Table.DeleteByPartitionIdAndSortId,
+			},
+			expectedKind:    models.ErrConflict,
+			expectedDeleted: true,
+			expectedVersion: 1,
+		},
+		{
+			name: "puts deleted with pre-delete version",
+			steps: []func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error{
+				Table.DeleteByPartitionIdAndSortId,
+				func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+					return table.PutByPartitionIdAndSortId(context.WithValue(ctx, "expectedVersion", 1), modelIdentifiers, logPayload(TEST_PERSON_ID, "2025-01-20", 3))
+				},
+			},
+			expectedKind:    models.ErrPreconditionFailed,
+			expectedDeleted: true,
+			expectedVersion: 1,
+		},
+		{
+			name: "restores",
+			steps: []func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error{
+				Table.DeleteByPartitionIdAndSortId,
+				Table.RestoreByPartitionIdAndSortId,
+			},
+			expectedVersion: 2,
+		},
+		{
+			name: "restores with matching version",
+			steps: []func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error{
+				Table.DeleteByPartitionIdAndSortId,
+				func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+					return table.RestoreByPartitionIdAndSortId(context.WithValue(ctx, "expectedVersion", 1), modelIdentifiers)
+				},
+			},
+			expectedVersion: 2,
+		},
+		{
+			name: "restores with stale version",
+			steps: []func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error{
+				Table.DeleteByPartitionIdAndSortId,
+				func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error {
+					return table.RestoreByPartitionIdAndSortId(context.WithValue(ctx, "expectedVersion", 2), modelIdentifiers)
+				},
+			},
+			expectedKind:    models.ErrPreconditionFailed,
+			expectedDeleted: true,
+			expectedVersion: 1,
+		},
+		{
+			name: "restores undeleted",
+			steps: []func(table Table, ctx context.Context, modelIdentifiers *models.ModelIdentifiers) error{
+				Table.RestoreByPartitionIdAndSortId,
+			},
+			expectedKind:    models.ErrConflict,
+			expectedVersion: 1,
+		},
+	}
+
+	for _, testTable := range testTables() {
+		for _, test := range tests {
+			t.Run(testTable.name+"/"+test.name, func(t *testing.T) {
+				table := testTable.newTable(t)
+				repository := newLogRepository(table)
+				modelIdentifiers := logIdentifiers("j1", "l1")
+
+				err := table.PutByPartitionIdAndSortId(testContext(0), modelIdentifiers, logPayload(TEST_PERSON_ID, "2025-01-20", 2))
+				assertErrorKind(t, err, nil)
+
+				for step, run := range test.steps {
+					err = run(table, testContext(step+1), modelIdentifiers)
+					if step < len(test.steps)-1 {
+						assertErrorKind(t, err, nil)
+					}
+				}
+				assertErrorKind(t, err, test.expectedKind)
+
+				logData, err := repository.GetByPartitionIdAndSortId(testContext(10), modelIdentifiers)
+				assertErrorKind(t, err, nil)
+				if (logData.DeletedAt != "") != test.expectedDeleted {
+					t.Fatalf("expected deleted %t, got deleted at %q", test.expectedDeleted, logData.DeletedAt)
+				}
+				if logData.Version != test.expectedVersion {
+					t.Fatalf("expected version %d, got %d", test.expectedVersion, logData.Version)
+				}
+				createdAt := time.UnixMilli(TEST_REQUESTED_AT).Format(time.RFC3339)
+				if logData.CreatedAt != createdAt {
+					t.Fatalf("expected created at %q, got %q", createdAt, logData.CreatedAt)
+				}
+				if (logData.RestoredAt != "") != (test.expectedVersion == 2) {
+					t.Fatalf("expected restored %t, got restored at %q", test.expectedVersion == 2, logData.RestoredAt)
+				}
+
+				versionIdentifiers := *modelIdentifiers
+				versionIdentifiers.Version = test.expectedVersion
+				versionData, err := repository.GetVersionByPartitionIdAndSortId(testContext(10), &versionIdentifiers)
+				assertErrorKind(t, err, nil)
+				if versionData.DeletedAt != logData.DeletedAt || versionData.RestoredAt != logData.RestoredAt {
+					t.Fatalf("expected version %d to match root, got %+v", test.expectedVersion, versionData)
+				}
+
+				logPage, err := repository.GetBySortType(testContext(10), modelIdentifiers, &models.ModelQuery{
+					Deleted:      models.DELETED_FILTER_FALSE,
+					WorkDateFrom: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+				})
+				assertErrorKind(t, err, nil)
+				if (len(logPage.Items) == 0) != test.expectedDeleted {
+					t.Fatalf("expected deleted %t, got work date index items %v", test.expectedDeleted, logIds(logPage.Items))
+				}
+			})
+		}
+	}
+}
+
+func TestMissingItem(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, repository Repository[*models.LogData], modelIdentifiers *models.ModelIdentifiers) error
+	}{
+		{name: "delete", run: func(ctx context.Context, repository Repository[*models.LogData], modelIdentifiers *models.ModelIdentifiers) error {
+			return repository.DeleteByPartitionIdAndSortId(ctx, modelIdentifiers)
+		}},
+		{name: "restore", run: func(ctx context.Context, repository Repository[*models.LogData], modelIdentifiers *models.ModelIdentifiers) error {
+			return repository.RestoreByPartitionIdAndSortId(ctx, modelIdentifiers)
+		}},
+		{name: "revert", run: func(ctx context.Context, repository Repository[*models.LogData], modelIdentifiers *models.ModelIdentifiers) error {
+			return repository.RevertByPartitionIdAndSortId(ctx, modelIdentifiers)
+		}},
+		{name: "get", run: func(ctx context.Context, repository Repository[*models.LogData], modelIdentifiers *models.ModelIdentifiers) error {
+			_, err := repository.GetByPartitionIdAndSortId(ctx, modelIdentifiers)
+			return err
+		}},
+		{name: "get versions", run: func(ctx context.Context, repository Repository[*models.LogData], modelIdentifiers *models.ModelIdentifiers) error {
+			_, err := repository.GetVersionsByPartitionIdAndSortId(ctx, modelIdentifiers)
+			return err
+		}},
+		{name: "get diff", run: func(ctx context.Context, repository Repository[*models.LogData], modelIdentifiers *models.ModelIdentifiers) error {
+			_, err := repository.GetDiffByPartitionIdAndSortId(ctx, modelIdentifiers, 1, 2)
+			return err
+		}},
+	}
+
+	for _, testTable := range testTables() {
+		for _, test := range tests {
+			t.Run(testTable.name+"/"+test.name, func(t *testing.T) {
+				repository := newLogRepository(testTable.newTable(t))
+				modelIdentifiers := logIdentifiers("j1", "missing")
+				modelIdentifiers.Version = 1
+
+				err := test.run(testContext(0), repository, modelIdentifiers)
+				assertErrorKind(t, err, models.ErrNotFound)
+			})
+		}
+	}
+}
+
+func TestRevertByPartitionIdAndSortId(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         int
+		deleted         bool
+		expectedKind    error
+		expectedVersion int
+		expectedHours   float64
+	}{
+		{name: "reverts to earlier version", version: 1, expectedVersion: 3, expectedHours: 2},
+		{name: "reverts to latest version", version: 2, expectedVersion: 3, expectedHours: 4},
+		{name: "missing version", version: 5, expectedKind: models.ErrNotFound, expectedVersion: 2, expectedHours: 4},
+		{name: "deleted item", version: 1, deleted: true, expectedKind: models.ErrConflict, expectedVersion: 2, expectedHours: 4},
+	}
+
+	for _, testTable := range testTables() {
+		for _, test := range tests {
+			t.Run(testTable.name+"/"+test.name, func(t *testing.T) {
+				table := testTable.newTable(t)
+				repository := newLogRepository(table)
+				modelIdentifiers := logIdentifiers("j1", "l1")
+
+				err := table.PutByPartitionIdAndSortId(testContext(0), modelIdentifiers, logPayload(TEST_PERSON_ID, "2025-01-20", 2))
+				assertErrorKind(t, err, nil)
+				err = table.PutByPartitionIdAndSortId(testContext(1), modelIdentifiers, logPayload(TEST_PERSON_ID, "2025-01-21", 4))
+				assertErrorKind(t, err, nil)
+				if test.deleted {
+					err = table.DeleteByPartitionIdAndSortId(testContext(2), modelIdentifiers)
+					assertErrorKind(t, err, nil)
+				}
+
+				versionIdentifiers := *modelIdentifiers
+				versionIdentifiers.Version = test.version
+				err = repository.RevertByPartitionIdAndSortId(testContext(3), &versionIdentifiers)
+				assertErrorKind(t, err, test.expectedKind)
+
+				logData, err := repository.GetByPartitionIdAndSortId(testContext(4), modelIdentifiers)
+				assertErrorKind(t, err, nil)
+				if logData.Version != test.expectedVersion || logData.Hours != test.expectedHours {
+					t.Fatalf("expected version %d with %v hours, got version %d with %v hours", test.expectedVersion, test.expectedHours, logData.Version, logData.Hours)
+				}
+
+				if test.expectedKind != nil {
+					return
+				}
+				diffs, err := repository.GetDiffByPartitionIdAndSortId(testContext(4), modelIdentifiers, test.version, test.expectedVersion)
+				assertErrorKind(t, err, nil)
+				for _, diff := range diffs {
+					if diff.Field == "hours" || diff.Field == "workDate" {
+						t.Fatalf("expected version %d to match version %d, got %+v", test.expectedVersion, test.version, diffs)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRevertInvalidPayload(t *testing.T) {
+	for _, testTable := range testTables() {
+		t.Run(testTable.name, func(t *testing.T) {
+			table := testTable.newTable(t)
+			repository := newLogRepository(table)
+			modelIdentifiers := logIdentifiers("j1", "l1")
+
+			err := table.PutByPartitionIdAndSortId(testContext(0), modelIdentifiers, logPayload(TEST_PERSON_ID, "2025-01-20", 30))
+			assertErrorKind(t, err, nil)
+			err = table.PutByPartitionIdAndSortId(testContext(1), modelIdentifiers, logPayload(TEST_PERSON_ID, "2025-01-20", 3))
+			assertErrorKind(t, err, nil)
+
+			versionIdentifiers := *modelIdentifiers
+			versionIdentifiers.Version = 1
+			err = repository.RevertByPartitionIdAndSortId(testContext(2), &versionIdentifiers)
+			assertErrorKind(t, err, models.ErrValidation)
+		})
+	}
+}
+
+func TestGetBySortType(t *testing.T) {
+	tests := []struct {
+		name         string
+		modelQuery   *models.ModelQuery
+		expectedKind error
+		expectedIds  []string
+	}{
+		{name: "all", modelQuery: new(models.ModelQuery), expectedIds: []string{"l5", "l4", "l2", "l1", "l3"}},
+		{name: "not deleted", modelQuery: &models.ModelQuery{Deleted: models.DELETED_FILTER_FALSE}, expectedIds: []string{"l5", "l4", "l2", "l1"}},
+		{name: "only deleted", modelQuery: &models.ModelQuery{Deleted: models.DELETED_FILTER_ONLY}, expectedIds: []string{"l3"}},
+		{name: "string filter", modelQuery: &models.ModelQuery{Filters: map[string]string{"personId": TEST_OTHER_PERSON_ID}}, expectedIds: []string{"l5", "l2"}},
+		{name: "number filter", modelQuery: &models.ModelQuery{Filters: map[string]string{"hours": "4.0"}}, expectedIds: []string{"l3"}},
+		{name: "created after", modelQuery: &models.ModelQuery{CreatedAfter: time.UnixMilli(TEST_REQUESTED_AT + 2000)}, expectedIds: []string{"l5", "l4"}},
+		{name: "created before", modelQuery: &models.ModelQuery{CreatedBefore: time.UnixMilli(TEST_REQUESTED_AT + 2000)}, expectedIds: []string{"l2", "l1"}},
+		{name: "unsupported filter", modelQuery: &models.ModelQuery{Filters: map[string]string{"workDateKey": "2025-01-20"}}, expectedKind: models.ErrValidation},
+		{name: "invalid number filter", modelQuery: &models.ModelQuery{Filters: map[string]string{"hours": "many"}}, expectedKind: models.ErrValidation},
+		{name: "work date range", modelQuery: &models.ModelQuery{WorkDateFrom: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), WorkDateTo: time.Date(2025, 1, 22, 0, 0, 0, 0, time.UTC)}, expectedIds: []string{"l2", "l1", "l3"}},
+		{name: "work date from", modelQuery: &models.ModelQuery{WorkDateFrom: time.Date(2025, 1, 22, 0, 0, 0, 0, time.UTC), Deleted: models.DELETED_FILTER_FALSE}, expectedIds: []string{"l4"}},
+		{name: "work date to", modelQuery: &models.ModelQuery{WorkDateTo: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)}, expectedIds: []string{"l5", "l1"}},
+		{name: "sorted", modelQuery: &models.ModelQuery{Sort: []models.SortField{{Field: "hours", Descending: true}}}, expectedIds: []string{"l5", "l4", "l3", "l2", "l1"}},
+		{name: "as of", modelQuery: &models.ModelQuery{AsOf: time.UnixMilli(TEST_REQUESTED_AT + 1500), Sort: []models.SortField{{Field: "logId"}}}, expectedIds: []string{"l1", "l2"}},
+		{name: "as of with filter", modelQuery: &models.ModelQuery{AsOf: time.UnixMilli(TEST_REQUESTED_AT), Filters: map[string]string{"hours": "2"}}, expectedKind: models.ErrValidation},
+	}
+
+	for _, testTable := range testTables() {
+		t.Run(testTable.name, func(t *testing.T) {
+			table := testTable.newTable(t)
+			putLogs(t, table)
+			repository := newLogRepository(table)
+
+			for _, test := range tests {
+				t.Run(test.name, func(t *testing.T) {
+					logPage, err := repository.GetBySortType(testContext(20), &models.ModelIdentifiers{SortType: models.ModelTypeLog}, test.modelQuery)
+					assertErrorKind(t, err, test.expectedKind)
+					if test.expectedKind != nil {
+						return
+					}
+					assertLogIds(t, logPage.Items, test.expectedIds)
+				})
+			}
+		})
+	}
+}
+
+func TestGetByPartitionIdAndPersonId(t *testing.T) {
+	tests := []struct {
+		name             string
+		modelIdentifiers *models.ModelIdentifiers
+		modelQuery       *models.ModelQuery
+		expectedKind     error
+		expectedIds      []string
+	}{
+		{name: "partition", modelIdentifiers: logIdentifiers("j2", ""), modelQuery: new(models.ModelQuery), expectedIds: []string{"l5", "l4"}},
+		{name: "partition work date range", modelIdentifiers: logIdentifiers("j1", ""), modelQuery: &models.ModelQuery{WorkDateFrom: time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC)}, expectedIds: []string{"l2", "l3"}},
+		{name: "partition filter", modelIdentifiers: logIdentifiers("j1", ""), modelQuery: &models.ModelQuery{Filters: map[string]string{"personId": TEST_PERSON_ID}, Deleted: models.DELETED_FILTER_FALSE}, expectedIds: []string{"l1"}},
+		{name: "person", modelIdentifiers: &models.ModelIdentifiers{PartitionType: models.ModelTypePerson, PartitionId: TEST_PERSON_ID, SortType: models.ModelTypeLog}, modelQuery: new(models.ModelQuery), expectedIds: []string{"l4", "l1", "l3"}},
+		{name: "person work date range", modelIdentifiers: &models.ModelIdentifiers{PartitionType: models.ModelTypePerson, PartitionId: TEST_OTHER_PERSON_ID, SortType: models.ModelTypeLog}, modelQuery: &models.ModelQuery{WorkDateTo: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)}, expectedIds: []string{"l5"}},
+		{name: "person filter", modelIdentifiers: &models.ModelIdentifiers{PartitionType: models.ModelTypePerson, PartitionId: TEST_PERSON_ID, SortType: models.ModelTypeLog}, modelQuery: &models.ModelQuery{Filters: map[string]string{"personId": TEST_PERSON_ID}}, expectedKind: models.ErrValidation},
+	}
+
+	for _, testTable := range testTables() {
+		t.Run(testTable.name, func(t *testing.T) {
+			table := testTable.newTable(t)
+			putLogs(t, table)
+			repository := newLogRepository(table)
+
+			for _, test := range tests {
+				t.Run(test.name, func(t *testing.T) {
+					var logPage *models.TypedModelPage[*models.LogData]
+					var err error
+					if test.modelIdentifiers.PartitionType == models.ModelTypePerson {
+						logPage, err = repository.GetByPersonId(testContext(20), test.modelIdentifiers, test.modelQuery)
+					} else {
+						logPage, err = repository.GetByPartitionId(testContext(20), test.modelIdentifiers, test.modelQuery)
+					}
+					assertErrorKind(t, err, test.expectedKind)
+					if test.expectedKind != nil {
+						return
+					}
+					assertLogIds(t, logPage.Items, test.expectedIds)
+				})
+			}
+		})
+	}
+}
+
+func TestPagination(t *testing.T) {
+	tests := []struct {
+		name          string
+		modelQuery    models.ModelQuery
+		expectedPages [][]string
+	}{
+		{name: "limit", modelQuery: models.ModelQuery{Limit: 2}, expectedPages: [][]string{{"l2", "l1"}, {"l4", "l3"}, {"l5"}}},
+		{name: "limit after filter", modelQuery: models.ModelQuery{Limit: 2, Deleted: models.DELETED_FILTER_FALSE}, expectedPages: [][]string{{"l2", "l1"}, {"l5", "l4"}, {}}},
+		{name: "limit work date range", modelQuery: models.ModelQuery{Limit: 2, WorkDateFrom: time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)}, expectedPages: [][]string{{"l5", "l1"}, {"l2", "l3"}, {"l4"}}},
+		{name: "limit sorted", modelQuery: models.ModelQuery{Limit: 2, Sort: []models.SortField{{Field: "hours", Descending: true}}}, expectedPages: [][]string{{"l5", "l4"}, {"l3", "l2"}, {"l1"}}},
+	}
+
+	for _, testTable := range testTables() {
+		t.Run(testTable.name, func(t *testing.T) {
+			table := testTable.newTable(t)
+			putLogs(t, table)
+			repository := newLogRepository(table)
+
+			for _, test := range tests {
+				t.Run(test.name, func(t *testing.T) {
+					modelQuery := test.modelQuery
+					for idx, expectedIds := range test.expectedPages {
+						logPage, err := repository.GetBySortType(testContext(20), &models.ModelIdentifiers{SortType: models.ModelTypeLog}, &modelQuery)
+						assertErrorKind(t, err, nil)
+						assertLogIds(t, logPage.Items, expectedIds)
+
+						if idx == len(test.expectedPages)-1 {
+							if logPage.NextCursor != "" {
+								t.Fatalf("expected last page to have no cursor")
+							}
+							break
+						}
+						if logPage.NextCursor == "" {
+							t.Fatalf("expected page %d to have a cursor", idx+1)
+						}
+						modelQuery.Cursor = logPage.NextCursor
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestCursorScope(t *testing.T) {
+	for _, testTable := range testTables() {
+		t.Run(testTable.name, func(t *testing.T) {
+			table := testTable.newTable(t)
+			putLogs(t, table)
+			repository := newLogRepository(table)
+
+			logPage, err := repository.GetBySortType(testContext(20), &models.ModelIdentifiers{SortType: models.ModelTypeLog}, &models.ModelQuery{Limit: 1})
+			assertErrorKind(t, err, nil)
+
+			_, err = repository.GetBySortType(testContext(20), &models.ModelIdentifiers{SortType: models.ModelTypeLog}, &models.ModelQuery{Limit: 1, Cursor: logPage.NextCursor, Deleted: models.DELETED_FILTER_FALSE})
+			assertErrorKind(t, err, models.ErrValidation)
+
+			_, err = repository.GetByPartitionId(testContext(20), logIdentifiers("j1", ""), &models.ModelQuery{Limit: 1, Cursor: logPage.NextCursor})
+			assertErrorKind(t, err, models.ErrValidation)
+		})
+	}
+}
+
+func TestSingletonVersions(t *testing.T) {
+	tests := []struct {
+		name          string
+		payloads      []*models.JobMetadataPayload
+		expectedNames []string
+	}{
+		{name: "single version", payloads: []*models.JobMetadataPayload{{Name: "Roof"}}, expectedNames: []string{"Roof"}},
+		{name: "many versions", payloads: []*models.JobMetadataPayload{{Name: "Roof"}, {Name: "Deck"}, {Name: "Porch"}}, expectedNames: []string{"Porch", "Deck", "Roof"}},
+	}
+
+	for _, testTable := range testTables() {
+		for _, test := range tests {
+			t.Run(testTable.name+"/"+test.name, func(t *testing.T) {
+				table := testTable.newTable(t)
+				repository := newJobMetadataRepository(table)
+				modelIdentifiers := jobMetadataIdentifiers("j1")
+
+				for step, payload := range test.payloads {
+					err := table.PutByPartitionIdAndSortId(expectedVersionContext(step, step), modelIdentifiers, payload)
+					assertErrorKind(t, err, nil)
+				}
+
+				jobMetadataDatas, err := repository.GetVersionsByPartitionIdAndSortId(testContext(10), modelIdentifiers)
+				assertErrorKind(t, err, nil)
+				names := make([]string, 0, len(jobMetadataDatas))
+				for _, jobMetadataData := range jobMetadataDatas {
+					names = append(names, jobMetadataData.Name)
+				}
+				if fmt.Sprint(names) != fmt.Sprint(test.expectedNames) {
+					t.Fatalf("expected names %v, got %v", test.expectedNames, names)
+				}
+
+				jobMetadataPage, err := repository.GetBySortType(testContext(10), modelIdentifiers, new(models.ModelQuery))
+				assertErrorKind(t, err, nil)
+				if len(jobMetadataPage.Items) != 1 || jobMetadataPage.Items[0].Name != test.expectedNames[0] {
+					t.Fatalf("expected only the latest root in the ModelType-SK index, got %+v", jobMetadataPage.Items)
+				}
+			})
+		}
+	}
+}
+
+type countingTable struct {
+	Table
+	queries int
+}
+
+func (t *countingTable) QueryItemsBySortType(ctx context.Context, modelIdentifiers *models.ModelIdentifiers, itemCodec ItemCodec, modelQuery *models.ModelQuery) (*ItemPage, error) {
+	t.queries++
+	return t.Table.QueryItemsBySortType(ctx, modelIdentifiers, itemCodec, modelQuery)
+}
+
+func TestRepositoryOverWrappedTable(t *testing.T) {
+	for _, testTable := range testTables() {
+		t.Run(testTable.name, func(t *testing.T) {
+			table := &countingTable{Table: testTable.newTable(t)}
+			putLogs(t, table)
+			repository := newLogRepository(table)
+
+			logPage, err := repository.GetBySortType(testContext(20), &models.ModelIdentifiers{SortType: models.ModelTypeLog}, &models.ModelQuery{Sort: []models.SortField{{Field: "hours", Descending: true}}})
+			assertErrorKind(t, err, nil)
+			assertLogIds(t, logPage.Items, []string{"l5", "l4", "l3", "l2", "l1"})
+			if table.queries != 1 {
+				t.Fatalf("expected 1 query through the wrapped table, got %d", table.queries)
+			}
+		})
+	}
+}
+
+func putRawItems(t *testing.T, table Table, items []map[string]types.AttributeValue) {
+	for _, item := range items {
+		switch typedTable := table.(type) {
+		case *MemoryTable:
+			typedTable.putItem(item)
+		case *DynamoDBTable:
+			_, err := typedTable.Client.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String(typedTable.TableName), Item: item})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func legacyLogItem(version int, latestVersion int, hours string, createdAt string) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		"PK":        &types.AttributeValueMemberS{Value: models.EncodePartitionKey(models.ModelTypeJob, "j1")},
+		"SK":        &types.AttributeValueMemberS{Value: models.EncodeSortKey(version, models.ModelTypeLog, "l1")},
+		"ModelType": &types.AttributeValueMemberS{Value: string(models.ModelTypeLog)},
+		"PersonId":  &types.AttributeValueMemberS{Value: TEST_PERSON_ID},
+		"Hours":     &types.AttributeValueMemberN{Value: hours},
+		"CreatedAt": &types.AttributeValueMemberS{Value: createdAt},
+		"CreatedBy": &types.AttributeValueMemberS{Value: TEST_REQUESTED_BY},
+	}
+	if latestVersion > 0 {
+		item["LatestVersion"] = &types.AttributeValueMemberN{Value: fmt.Sprint(latestVersion)}
+	}
+	return item
+}
+
+func TestBackfillRootItems(t *testing.T) {
+	for _, testTable := range testTables() {
+		t.Run(testTable.name, func(t *testing.T) {
+			table := testTable.newTable(t)
+			putRawItems(t, table, []map[string]types.AttributeValue{
+				legacyLogItem(0, 2, "3", "2025-01-21T09:00:00Z"),
+				legacyLogItem(1, 0, "2", "2025-01-20T09:00:00Z"),
+				legacyLogItem(2, 0, "3", "2025-01-21T09:00:00Z"),
+			})
+			repository := newLogRepository(table)
+			workDateQuery := &models.ModelQuery{WorkDateFrom: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), WorkDateTo: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)}
+
+			logPage, err := repository.GetBySortType(testContext(10), &models.ModelIdentifiers{SortType: models.ModelTypeLog}, workDateQuery)
+			assertErrorKind(t, err, nil)
+			assertLogIds(t, logPage.Items, []string{})
+
+			backfilled, err := table.BackfillRootItems(context.Background())
+			assertErrorKind(t, err, nil)
+			if backfilled != 1 {
+				t.Fatalf("expected 1 backfilled root item, got %d", backfilled)
+			}
+
+			logPage, err = repository.GetBySortType(testContext(10), &models.ModelIdentifiers{SortType: models.ModelTypeLog}, workDateQuery)
+			assertErrorKind(t, err, nil)
+			assertLogIds(t, logPage.Items, []string{"l1"})
+			if logPage.Items[0].WorkDate != "2025-01-20" || logPage.Items[0].Hours != 3 {
+				t.Fatalf("expected the latest hours on the first version work date, got %+v", logPage.Items[0])
+			}
+
+			logPage, err = repository.GetByPersonId(testContext(10), &models.ModelIdentifiers{PartitionType: models.ModelTypePerson, PartitionId: TEST_PERSON_ID, SortType: models.ModelTypeLog}, new(models.ModelQuery))
+			assertErrorKind(t, err, nil)
+			assertLogIds(t, logPage.Items, []string{"l1"})
+
+			logPage, err = repository.GetBySortType(testContext(10), &models.ModelIdentifiers{SortType: models.ModelTypeLog}, &models.ModelQuery{CreatedBefore: time.Date(2025, 1, 20, 12, 0, 0, 0, time.UTC)})
+			assertErrorKind(t, err, nil)
+			assertLogIds(t, logPage.Items, []string{"l1"})
+
+			backfilled, err = table.BackfillRootItems(context.Background())
+			assertErrorKind(t, err, nil)
+			if backfilled != 0 {
+				t.Fatalf("expected nothing left to backfill, got %d", backfilled)
+			}
+		})
+	}
+}
+
+func TestCreatedFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		modelQuery  *models.ModelQuery
+		expectedIds []string
+	}{
+		{name: "created after", modelQuery: &models.ModelQuery{CreatedAfter: time.UnixMilli(TEST_REQUESTED_AT + 1000)}, expectedIds: []string{"l2"}},
+		{name: "created before", modelQuery: &models.ModelQuery{CreatedBefore: time.UnixMilli(TEST_REQUESTED_AT + 1000)}, expectedIds: []string{"l1"}},
+	}
+
+	for _, testTable := range testTables() {
+		t.Run(testTable.name, func(t *testing.T) {
+			table := testTable.newTable(t)
+			repository := newLogRepository(table)
+
+			err := table.PutByPartitionIdAndSortId(testContext(0), logIdentifiers("j1", "l1"), logPayload(TEST_PERSON_ID, "2025-01-20", 2))
+			assertErrorKind(t, err, nil)
+			err = table.PutByPartitionIdAndSortId(testContext(2), logIdentifiers("j1", "l2"), logPayload(TEST_PERSON_ID, "2025-01-21", 3))
+			assertErrorKind(t, err, nil)
+			err = table.PutByPartitionIdAndSortId(testContext(3), logIdentifiers("j1", "l1"), logPayload(TEST_PERSON_ID, "2025-01-20", 4))
+			assertErrorKind(t, err, nil)
+			err = table.DeleteByPartitionIdAndSortId(testContext(4), logIdentifiers("j1", "l1"))
+			assertErrorKind(t, err, nil)
+			err = table.RestoreByPartitionIdAndSortId(testContext(5), logIdentifiers("j1", "l1"))
+			assertErrorKind(t, err, nil)
+
+			for _, test := range tests {
+				t.Run(test.name, func(t *testing.T) {
+					logPage, err := repository.GetBySortType(testContext(10), &models.ModelIdentifiers{SortType: models.ModelTypeLog}, test.modelQuery)
+					assertErrorKind(t, err, nil)
+					assertLogIds(t, logPage.Items, test.expectedIds)
+				})
+			}
+		})
+	}
+}
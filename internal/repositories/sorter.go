@@ -1,97 +1,122 @@
 package repositories
 
 import (
-	"log"
-	"reflect"
+	"fmt"
 	"sort"
-	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
 	"j-and-a/internal/models"
 )
 
-type lessFunc func(d1, d2 models.ModelData) bool
+type lessFunc[D models.ModelData] func(d1, d2 D) (bool, error)
+
+type multiSorter[D models.ModelData] struct {
+	modelDatas []D
+	items      []map[string]types.AttributeValue
+	lesses     []lessFunc[D]
+	err        error
+}
 
-type multiSorter struct {
-	modelDatas []models.ModelData
-	lesses     []lessFunc
+func (ms *multiSorter[D]) Sort(modelDatas []D) error {
+	return ms.SortItems(modelDatas, nil)
 }
 
-func (ms *multiSorter) Sort(modelDatas []models.ModelData) {
+func (ms *multiSorter[D]) SortItems(modelDatas []D, items []map[string]types.AttributeValue) error {
 	ms.modelDatas = modelDatas
+	ms.items = items
+	ms.err = nil
 	sort.Sort(ms)
+	return ms.err
 }
 
-func OrderedBy(lesses ...lessFunc) *multiSorter {
-	return &multiSorter{
+func OrderedBy[D models.ModelData](lesses ...lessFunc[D]) *multiSorter[D] {
+	return &multiSorter[D]{
 		lesses: lesses,
 	}
 }
 
-func (ms *multiSorter) Len() int {
+func (ms *multiSorter[D]) Len() int {
 	return len(ms.modelDatas)
 }
 
-func (ms *multiSorter) Swap(i, j int) {
+func (ms *multiSorter[D]) Swap(i, j int) {
 	ms.modelDatas[i], ms.modelDatas[j] = ms.modelDatas[j], ms.modelDatas[i]
+	if ms.items != nil {
+		ms.items[i], ms.items[j] = ms.items[j], ms.items[i]
+	}
 }
 
-func (ms *multiSorter) Less(i, j int) bool {
+func (ms *multiSorter[D]) Less(i, j int) bool {
 	p, q := ms.modelDatas[i], ms.modelDatas[j]
 	var k int
 	for k = 0; k < len(ms.lesses)-1; k++ {
 		less := ms.lesses[k]
+		pLessQ, err := less(p, q)
+		if err != nil {
+			ms.err = err
+			return false
+		}
+		qLessP, err := less(q, p)
+		if err != nil {
+			ms.err = err
+			return false
+		}
 		switch {
-		case less(p, q):
+		case pLessQ:
 			return true
-		case less(q, p):
+		case qLessP:
 			return false
 		}
 	}
-	return ms.lesses[k](p, q)
+	isLess, err := ms.lesses[k](p, q)
+	if err != nil {
+		ms.err = err
+		return false
+	}
+	return isLess
 }
 
-func isDeleted(d1, d2 models.ModelData) bool {
-	v1 := reflect.ValueOf(d1)
-	v2 := reflect.ValueOf(d2)
-	if v1.Kind() != reflect.Pointer || v2.Kind() != reflect.Pointer {
-		log.Fatalln("model data must be pointer to struct")
-	}
-	v1 = v1.Elem()
-	v2 = v2.Elem()
-	if v1.Kind() != reflect.Struct || v2.Kind() != reflect.Struct {
-		log.Fatalln("model data must be pointer to struct")
-	}
-	v1 = v1.FieldByName("DeletedAt")
-	v2 = v2.FieldByName("DeletedAt")
-	return v1.Len() < v2.Len()
+func isDeleted[D models.ModelData](d1, d2 D) (bool, error) {
+	return len(d1.Audit().DeletedAt) < len(d2.Audit().DeletedAt), nil
 }
 
-func updatedAt(d1, d2 models.ModelData) bool {
-	v1 := reflect.ValueOf(d1)
-	v2 := reflect.ValueOf(d2)
-	if v1.Kind() != reflect.Pointer || v2.Kind() != reflect.Pointer {
-		log.Fatalln("model data must be pointer to struct")
-	}
-	v1 = v1.Elem()
-	v2 = v2.Elem()
-	if v1.Kind() != reflect.Struct || v2.Kind() != reflect.Struct {
-		log.Fatalln("model data must be pointer to struct")
+func updatedAt[D models.ModelData](d1, d2 D) (bool, error) {
+	t1, err := d1.Audit().UpdatedAt()
+	if err != nil {
+		return false, err
 	}
-	updatedAt1 := v1.FieldByName("DeletedAt")
-	if updatedAt1.Len() == 0 {
-		updatedAt1 = v1.FieldByName("CreatedAt")
+	t2, err := d2.Audit().UpdatedAt()
+	if err != nil {
+		return false, err
 	}
-	updatedAt2 := v2.FieldByName("DeletedAt")
-	if updatedAt2.Len() == 0 {
-		updatedAt2 = v2.FieldByName("CreatedAt")
+	return t1.After(t2), nil
+}
+
+func version[D models.ModelData](d1, d2 D) (bool, error) {
+	return d1.Audit().Version > d2.Audit().Version, nil
+}
+
+func byField[D models.TypedModelData[D]](sortField models.SortField) lessFunc[D] {
+	return func(d1, d2 D) (bool, error) {
+		if sortField.Descending {
+			d1, d2 = d2, d1
+		}
+		comparison, ok := d1.CompareField(d2, sortField.Field)
+		if !ok {
+			return false, fmt.Errorf("model data missing sort field %s", sortField.Field)
+		}
+		return comparison < 0, nil
 	}
-	t1, err := time.Parse(time.RFC3339, updatedAt1.String())
-	if err != nil {
-		log.Fatalln(err)
+}
+
+func sortLesses[D models.TypedModelData[D]](sortFields []models.SortField) []lessFunc[D] {
+	if len(sortFields) == 0 {
+		return []lessFunc[D]{isDeleted[D], updatedAt[D]}
 	}
-	t2, err := time.Parse(time.RFC3339, updatedAt2.String())
-	if err != nil {
-		log.Fatalln(err)
+	lesses := make([]lessFunc[D], len(sortFields))
+	for idx, sortField := range sortFields {
+		lesses[idx] = byField[D](sortField)
 	}
-	return t1.After(t2)
+	return lesses
 }
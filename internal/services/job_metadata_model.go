@@ -0,0 +1,26 @@
+package services
+
+import "j-and-a/internal/models"
+
+func init() {
+	RegisterModel(&Model[*models.JobMetadataData]{
+		SortType:      models.ModelTypeJobMetadata,
+		PartitionType: models.ModelTypeJob,
+		Singleton:     true,
+		Operations: []Operation{
+			OperationDeleteByPartitionIdAndSortId,
+			OperationGetByPartitionId,
+			OperationGetBySortType,
+			OperationGetDiffByPartitionIdAndSortId,
+			OperationGetVersionByPartitionIdAndSortId,
+			OperationGetVersionsByPartitionIdAndSortId,
+			OperationPutByPartitionIdAndSortId,
+			OperationRestoreByPartitionIdAndSortId,
+			OperationRevertByPartitionIdAndSortId,
+		},
+		Filters:    []string{"address", "client", "createdBy", "name", "status"},
+		NewPayload: func() models.ModelPayload { return new(models.JobMetadataPayload) },
+		NewItem:    func() models.TypedModelItem[*models.JobMetadataData] { return new(models.JobMetadataItem) },
+		NewData:    func() *models.JobMetadataData { return new(models.JobMetadataData) },
+	})
+}
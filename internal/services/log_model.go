@@ -0,0 +1,32 @@
+package services
+
+import "j-and-a/internal/models"
+
+func init() {
+	RegisterModel(&Model[*models.LogData]{
+		SortType:      models.ModelTypeLog,
+		PartitionType: models.ModelTypeJob,
+		PersonIndexed: true,
+		WorkDated:     true,
+		Operations: []Operation{
+			OperationDeleteByPartitionIdAndSortId,
+			OperationGetByPartitionId,
+			OperationGetByPartitionIdAndSortId,
+			OperationGetBySortType,
+			OperationGetDiffByPartitionIdAndSortId,
+			OperationGetSummaryByPartitionId,
+			OperationGetVersionByPartitionIdAndSortId,
+			OperationGetVersionsByPartitionIdAndSortId,
+			OperationPutByPartitionIdAndSortId,
+			OperationRestoreByPartitionIdAndSortId,
+			OperationRevertByPartitionIdAndSortId,
+		},
+		Filters:    []string{"createdBy", "hours", "personId", "workDate"},
+		NewPayload: func() models.ModelPayload { return new(models.LogPayload) },
+		NewItem:    func() models.TypedModelItem[*models.LogData] { return new(models.LogItem) },
+		NewData:    func() *models.LogData { return new(models.LogData) },
+		Summarize: func(logDatas []*models.LogData) (interface{}, error) {
+			return models.SummarizeLogs(logDatas), nil
+		},
+	})
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"j-and-a/internal/models"
+	"j-and-a/internal/repositories"
+)
+
+type Model[D models.TypedModelData[D]] struct {
+	SortType      models.ModelType
+	PartitionType models.ModelType
+	Singleton     bool
+	PersonIndexed bool
+	WorkDated     bool
+	Operations    []Operation
+	Filters       []string
+	NewPayload    func() models.ModelPayload
+	NewItem       func() models.TypedModelItem[D]
+	NewData       func() D
+	Summarize     func(datas []D) (interface{}, error)
+	modelRoutes   []ModelRoute
+}
+
+type RegisteredModel interface {
+	NewModelData() models.ModelData
+	NewService(table repositories.Table, modelIdentifiers *models.ModelIdentifiers, route Route) (Service, error)
+}
+
+var modelRegistry = make(map[models.ModelType]RegisteredModel)
+
+func RegisterModel[D models.TypedModelData[D]](model *Model[D]) {
+	if _, ok := modelRegistry[model.SortType]; ok {
+		panic("model already registered: " + string(model.SortType))
+	}
+	model.modelRoutes = model.newModelRoutes()
+	modelRegistry[model.SortType] = model
+}
+
+func FindModel(sortType models.ModelType) (RegisteredModel, error) {
+	model, ok := modelRegistry[sortType]
+	if !ok {
+		return nil, models.NewModelError(models.ErrNotFound, "unsupported model type")
+	}
+	return model, nil
+}
+
+func (m *Model[D]) NewModelData() models.ModelData {
+	return m.NewData()
+}
+
+func (m *Model[D]) NewService(table repositories.Table, modelIdentifiers *models.ModelIdentifiers, route Route) (Service, error) {
+	modelService, err := NewModelService(table, m, modelIdentifiers, route)
+	if err != nil {
+		return nil, err
+	}
+	return &modelServiceAdapter[D]{ModelService: modelService}, nil
+}
+
+func (m *Model[D]) newModelRoutes() []ModelRoute {
+	modelRoutes := make([]ModelRoute, 0)
+	for _, route := range Routes {
+		if !slices.Contains(m.Operations, route.Operation) {
+			continue
+		}
+
+		if !route.RequiresPartition {
+			modelRoutes = append(modelRoutes, ModelRoute{RouteKey: route.RouteKey})
+			continue
+		}
+
+		isCollectionRoute := !m.Singleton && isCollectionOperation(route.Operation)
+		if (m.Singleton && route.RequiresSortId) || (!m.Singleton && isCollectionRoute == route.RequiresSortId) {
+			continue
+		}
+
+		partitionTypes := []models.ModelType{m.PartitionType}
+		if m.PersonIndexed && isCollectionRoute {
+			partitionTypes = append(partitionTypes, models.ModelTypePerson)
+		}
+		modelRoutes = append(modelRoutes, ModelRoute{RouteKey: route.RouteKey, PartitionTypes: partitionTypes})
+	}
+	return modelRoutes
+}
+
+func (m *Model[D]) validateFilters(modelQuery *models.ModelQuery) error {
+	for key := range modelQuery.Filters {
+		if !slices.Contains(m.Filters, key) {
+			return models.NewModelError(models.ErrValidation, fmt.Sprintf("unsupported filter %s", key))
+		}
+	}
+	if modelQuery.HasWorkDateRange() && !m.WorkDated {
+		return models.NewModelError(models.ErrValidation, "work date range is not supported by model")
+	}
+	return nil
+}
+
+func isCollectionOperation(operation Operation) bool {
+	return operation == OperationGetByPartitionId || operation == OperationGetSummaryByPartitionId
+}
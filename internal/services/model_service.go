@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+
+	"j-and-a/internal/models"
+	"j-and-a/internal/repositories"
+)
+
+func NewModelService[D models.TypedModelData[D]](table repositories.Table, model *Model[D], modelIdentifiers *models.ModelIdentifiers, route Route) (*ModelService[D], error) {
+	err := validateModelRoute(model.modelRoutes, route, modelIdentifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	repository := repositories.NewRepository(table, model.NewItem)
+
+	if model.Singleton {
+		modelIdentifiers.SortId = modelIdentifiers.PartitionId
+	}
+
+	return &ModelService[D]{Repository: repository, Model: model, ModelIdentifiers: modelIdentifiers}, nil
+}
+
+type ModelService[D models.TypedModelData[D]] struct {
+	Repository       repositories.Repository[D]
+	Model            *Model[D]
+	ModelIdentifiers *models.ModelIdentifiers
+}
+
+func (s *ModelService[D]) DeleteByPartitionIdAndSortId(ctx context.Context) error {
+	return s.Repository.DeleteByPartitionIdAndSortId(ctx, s.ModelIdentifiers)
+}
+
+func (s *ModelService[D]) GetByPartitionId(ctx context.Context, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error) {
+	err := s.Model.validateFilters(modelQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	err = models.ValidateSort(modelQuery.Sort, s.Model.NewData())
+	if err != nil {
+		return nil, err
+	}
+	return s.getPageByPartitionId(ctx, modelQuery)
+}
+
+func (s *ModelService[D]) GetByPartitionIdAndSortId(ctx context.Context) (D, error) {
+	return s.Repository.GetByPartitionIdAndSortId(ctx, s.ModelIdentifiers)
+}
+
+func (s *ModelService[D]) GetBySortType(ctx context.Context, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error) {
+	err := s.Model.validateFilters(modelQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	err = models.ValidateSort(modelQuery.Sort, s.Model.NewData())
+	if err != nil {
+		return nil, err
+	}
+	return s.Repository.GetBySortType(ctx, s.ModelIdentifiers, modelQuery)
+}
+
+func (s *ModelService[D]) GetDiffByPartitionIdAndSortId(ctx context.Context, fromVersion int, toVersion int) ([]models.ModelDiff, error) {
+	if fromVersion < 1 || toVersion < 1 {
+		return nil, models.NewModelError(models.ErrValidation, "invalid version")
+	}
+	return s.Repository.GetDiffByPartitionIdAndSortId(ctx, s.ModelIdentifiers, fromVersion, toVersion)
+}
+
+func (s *ModelService[D]) GetSingletonByPartitionId(ctx context.Context, modelQuery *models.ModelQuery) (D, error) {
+	err := s.Model.validateFilters(modelQuery)
+	if err != nil {
+		var data D
+		return data, err
+	}
+
+	if modelQuery.AsOf.IsZero() {
+		return s.Repository.GetByPartitionIdAndSortId(ctx, s.ModelIdentifiers)
+	}
+
+	modelPage, err := s.Repository.GetByPartitionId(ctx, s.ModelIdentifiers, modelQuery)
+	if err != nil {
+		var data D
+		return data, err
+	}
+	if len(modelPage.Items) == 0 {
+		var data D
+		return data, models.NewModelError(models.ErrNotFound, "item not found")
+	}
+	return modelPage.Items[0], nil
+}
+
+func (s *ModelService[D]) GetSummaryByPartitionId(ctx context.Context, modelQuery *models.ModelQuery) (interface{}, error) {
+	if s.Model.Summarize == nil {
+		return nil, models.NewModelError(models.ErrNotFound, "invalid service action")
+	}
+	if !modelQuery.AsOf.IsZero() {
+		return nil, models.NewModelError(models.ErrValidation, "as of is not supported by summary")
+	}
+
+	err := s.Model.validateFilters(modelQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryModelQuery := *modelQuery
+	summaryModelQuery.Deleted = models.DELETED_FILTER_FALSE
+	summaryModelQuery.Limit = 0
+	summaryModelQuery.Cursor = ""
+	summaryModelQuery.Sort = nil
+
+	datas := make([]D, 0)
+	for {
+		modelPage, err := s.getPageByPartitionId(ctx, &summaryModelQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		datas = append(datas, modelPage.Items...)
+
+		if modelPage.NextCursor == "" {
+			break
+		}
+		summaryModelQuery.Cursor = modelPage.NextCursor
+	}
+
+	return s.Model.Summarize(datas)
+}
+
+func (s *ModelService[D]) GetVersionByPartitionIdAndSortId(ctx context.Context) (D, error) {
+	return s.Repository.GetVersionByPartitionIdAndSortId(ctx, s.ModelIdentifiers)
+}
+
+func (s *ModelService[D]) GetVersionsByPartitionIdAndSortId(ctx context.Context) ([]D, error) {
+	return s.Repository.GetVersionsByPartitionIdAndSortId(ctx, s.ModelIdentifiers)
+}
+
+func (s *ModelService[D]) PutByPartitionIdAndSortId(ctx context.Context, requestBody string) error {
+	modelPayload := s.Model.NewPayload()
+	err := decodeModelPayload(requestBody, modelPayload)
+	if err != nil {
+		return err
+	}
+	return s.Repository.PutByPartitionIdAndSortId(ctx, s.ModelIdentifiers, modelPayload)
+}
+
+func (s *ModelService[D]) RestoreByPartitionIdAndSortId(ctx context.Context) error {
+	return s.Repository.RestoreByPartitionIdAndSortId(ctx, s.ModelIdentifiers)
+}
+
+func (s *ModelService[D]) RevertByPartitionIdAndSortId(ctx context.Context) error {
+	return s.Repository.RevertByPartitionIdAndSortId(ctx, s.ModelIdentifiers)
+}
+
+func (s *ModelService[D]) getPageByPartitionId(ctx context.Context, modelQuery *models.ModelQuery) (*models.TypedModelPage[D], error) {
+	if s.Model.PersonIndexed && s.ModelIdentifiers.PartitionType == models.ModelTypePerson {
+		return s.Repository.GetByPersonId(ctx, s.ModelIdentifiers, modelQuery)
+	}
+	return s.Repository.GetByPartitionId(ctx, s.ModelIdentifiers, modelQuery)
+}
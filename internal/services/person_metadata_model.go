@@ -0,0 +1,26 @@
+package services
+
+import "j-and-a/internal/models"
+
+func init() {
+	RegisterModel(&Model[*models.PersonMetadataData]{
+		SortType:      models.ModelTypePersonMetadata,
+		PartitionType: models.ModelTypePerson,
+		Singleton:     true,
+		Operations: []Operation{
+			OperationDeleteByPartitionIdAndSortId,
+			OperationGetByPartitionId,
+			OperationGetBySortType,
+			OperationGetDiffByPartitionIdAndSortId,
+			OperationGetVersionByPartitionIdAndSortId,
+			OperationGetVersionsByPartitionIdAndSortId,
+			OperationPutByPartitionIdAndSortId,
+			OperationRestoreByPartitionIdAndSortId,
+			OperationRevertByPartitionIdAndSortId,
+		},
+		Filters:    []string{"createdBy", "familyName", "givenName"},
+		NewPayload: func() models.ModelPayload { return new(models.PersonMetadataPayload) },
+		NewItem:    func() models.TypedModelItem[*models.PersonMetadataData] { return new(models.PersonMetadataItem) },
+		NewData:    func() *models.PersonMetadataData { return new(models.PersonMetadataData) },
+	})
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"j-and-a/internal/models"
+	"j-and-a/internal/repositories"
+)
+
+func NewReportService(table repositories.Table) *ReportService {
+	return &ReportService{Table: table}
+}
+
+type ReportService struct {
+	Table repositories.Table
+}
+
+func (s *ReportService) GetTimesheet(ctx context.Context, weekOf string) (*models.Timesheet, error) {
+	weekOfDate, err := time.Parse(time.DateOnly, weekOf)
+	if err != nil {
+		return nil, models.NewModelError(models.ErrValidation, "invalid week of")
+	}
+
+	weekStart := models.WeekStart(weekOfDate)
+	logDatas, err := getAllBySortType(ctx, s.Table, models.ModelTypeLog, func() models.TypedModelItem[*models.LogData] { return new(models.LogItem) }, &models.ModelQuery{
+		Deleted:      models.DELETED_FILTER_FALSE,
+		WorkDateFrom: weekStart,
+		WorkDateTo:   weekStart.AddDate(0, 0, models.TIMESHEET_DAYS-1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	personMetadataDatas, err := getAllBySortType(ctx, s.Table, models.ModelTypePersonMetadata, func() models.TypedModelItem[*models.PersonMetadataData] { return new(models.PersonMetadataItem) }, new(models.ModelQuery))
+	if err != nil {
+		return nil, err
+	}
+
+	jobMetadataDatas, err := getAllBySortType(ctx, s.Table, models.ModelTypeJobMetadata, func() models.TypedModelItem[*models.JobMetadataData] { return new(models.JobMetadataItem) }, new(models.ModelQuery))
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildTimesheet(weekStart, logDatas, personMetadataDatas, jobMetadataDatas), nil
+}
+
+func getAllBySortType[D models.TypedModelData[D]](ctx context.Context, table repositories.Table, sortType models.ModelType, newItem func() models.TypedModelItem[D], modelQuery *models.ModelQuery) ([]D, error) {
+	repository := repositories.NewRepository(table, newItem)
+	modelIdentifiers := &models.ModelIdentifiers{SortType: sortType}
+
+	datas := make([]D, 0)
+	for {
+		modelPage, err := repository.GetBySortType(ctx, modelIdentifiers, modelQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		datas = append(datas, modelPage.Items...)
+
+		if modelPage.NextCursor == "" {
+			break
+		}
+		modelQuery.Cursor = modelPage.NextCursor
+	}
+	return datas, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"slices"
+	"strings"
+
+	"j-and-a/internal/models"
+)
+
+type Operation string
+
+const (
+	OperationDeleteByPartitionIdAndSortId      Operation = "DeleteByPartitionIdAndSortId"
+	OperationGetByPartitionId                  Operation = "GetByPartitionId"
+	OperationGetByPartitionIdAndSortId         Operation = "GetByPartitionIdAndSortId"
+	OperationGetBySortType                     Operation = "GetBySortType"
+	OperationGetDiffByPartitionIdAndSortId     Operation = "GetDiffByPartitionIdAndSortId"
+	OperationGetSummaryByPartitionId           Operation = "GetSummaryByPartitionId"
+	OperationGetTimesheet                      Operation = "GetTimesheet"
+	OperationGetVersionByPartitionIdAndSortId  Operation = "GetVersionByPartitionIdAndSortId"
+	OperationGetVersionsByPartitionIdAndSortId Operation = "GetVersionsByPartitionIdAndSortId"
+	OperationPutByPartitionIdAndSortId         Operation = "PutByPartitionIdAndSortId"
+	OperationRestoreByPartitionIdAndSortId     Operation = "RestoreByPartitionIdAndSortId"
+	OperationRevertByPartitionIdAndSortId      Operation = "RevertByPartitionIdAndSortId"
+)
+
+type Route struct {
+	RouteKey          string
+	Operation         Operation
+	RequiresPartition bool
+	RequiresSortId    bool
+	RequiresVersion   bool
+}
+
+var Routes = []Route{
+	{RouteKey: "DELETE /{PartitionType}/{PartitionId}/{SortType}", Operation: OperationDeleteByPartitionIdAndSortId, RequiresPartition: true},
+	{RouteKey: "DELETE /{PartitionType}/{PartitionId}/{SortType}/{SortId}", Operation: OperationDeleteByPartitionIdAndSortId, RequiresPartition: true, RequiresSortId: true},
+	{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}", Operation: OperationGetByPartitionId, RequiresPartition: true},
+	{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/summary", Operation: OperationGetSummaryByPartitionId, RequiresPartition: true},
+	{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/versions", Operation: OperationGetVersionsByPartitionIdAndSortId, RequiresPartition: true},
+	{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/versions/diff", Operation: OperationGetDiffByPartitionIdAndSortId, RequiresPartition: true},
+	{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/versions/{Version}", Operation: OperationGetVersionByPartitionIdAndSortId, RequiresPartition: true, RequiresVersion: true},
+	{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}", Operation: OperationGetByPartitionIdAndSortId, RequiresPartition: true, RequiresSortId: true},
+	{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions", Operation: OperationGetVersionsByPartitionIdAndSortId, RequiresPartition: true, RequiresSortId: true},
+	{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions/diff", Operation: OperationGetDiffByPartitionIdAndSortId, RequiresPartition: true, RequiresSortId: true},
+	{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions/{Version}", Operation: OperationGetVersionByPartitionIdAndSortId, RequiresPartition: true, RequiresSortId: true, RequiresVersion: true},
+	{RouteKey: "GET /{SortType}", Operation: OperationGetBySortType},
+	{RouteKey: "GET /reports/timesheet", Operation: OperationGetTimesheet},
+	{RouteKey: "POST /{PartitionType}/{PartitionId}/{SortType}/restore", Operation: OperationRestoreByPartitionIdAndSortId, RequiresPartition: true},
+	{RouteKey: "POST /{PartitionType}/{PartitionId}/{SortType}/versions/{Version}/revert", Operation: OperationRevertByPartitionIdAndSortId, RequiresPartition: true, RequiresVersion: true},
+	{RouteKey: "POST /{PartitionType}/{PartitionId}/{SortType}/{SortId}/restore", Operation: OperationRestoreByPartitionIdAndSortId, RequiresPartition: true, RequiresSortId: true},
+	{RouteKey: "POST /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions/{Version}/revert", Operation: OperationRevertByPartitionIdAndSortId, RequiresPartition: true, RequiresSortId: true, RequiresVersion: true},
+	{RouteKey: "PUT /{PartitionType}/{PartitionId}/{SortType}", Operation: OperationPutByPartitionIdAndSortId, RequiresPartition: true},
+	{RouteKey: "PUT /{PartitionType}/{PartitionId}/{SortType}/{SortId}", Operation: OperationPutByPartitionIdAndSortId, RequiresPartition: true, RequiresSortId: true},
+}
+
+func FindRoute(routeKey string) (Route, error) {
+	for _, route := range Routes {
+		if route.RouteKey == routeKey {
+			return route, nil
+		}
+	}
+	return Route{}, models.NewModelError(models.ErrNotFound, "unsupported service action")
+}
+
+func (r Route) Method() string {
+	method, _, _ := strings.Cut(r.RouteKey, " ")
+	return method
+}
+
+func (r Route) Path() string {
+	_, path, _ := strings.Cut(r.RouteKey, " ")
+	return path
+}
+
+type ModelRoute struct {
+	RouteKey       string
+	PartitionTypes []models.ModelType
+}
+
+func validateModelRoute(modelRoutes []ModelRoute, route Route, modelIdentifiers *models.ModelIdentifiers) error {
+	modelRouteIdx := slices.IndexFunc(modelRoutes, func(modelRoute ModelRoute) bool {
+		return modelRoute.RouteKey == route.RouteKey
+	})
+	if modelRouteIdx < 0 {
+		allowedMethods := make([]string, 0)
+		for _, modelRoute := range modelRoutes {
+			allowedRoute := Route{RouteKey: modelRoute.RouteKey}
+			if allowedRoute.Path() == route.Path() {
+				allowedMethods = append(allowedMethods, allowedRoute.Method())
+			}
+		}
+		if len(allowedMethods) > 0 {
+			return &models.ModelError{Kind: models.ErrMethodNotAllowed, Message: "method not allowed", AllowedMethods: allowedMethods}
+		}
+		return models.NewModelError(models.ErrNotFound, "invalid service action")
+	}
+	modelRoute := modelRoutes[modelRouteIdx]
+
+	if route.RequiresPartition && !slices.Contains(modelRoute.PartitionTypes, modelIdentifiers.PartitionType) {
+		return models.NewModelError(models.ErrValidation, "invalid partition type")
+	}
+
+	if route.RequiresPartition && modelIdentifiers.PartitionId == "" {
+		return models.NewModelError(models.ErrValidation, "invalid partition ID")
+	}
+
+	if route.RequiresSortId && modelIdentifiers.SortId == "" {
+		return models.NewModelError(models.ErrValidation, "invalid sort ID")
+	}
+
+	if route.RequiresVersion && modelIdentifiers.Version < 1 {
+		return models.NewModelError(models.ErrValidation, "invalid version")
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"j-and-a/internal/models"
+)
+
+func TestValidateModelRoute(t *testing.T) {
+	modelRoutes := []ModelRoute{
+		{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}", PartitionTypes: []models.ModelType{models.ModelTypeJob, models.ModelTypePerson}},
+		{RouteKey: "DELETE /{PartitionType}/{PartitionId}/{SortType}/{SortId}", PartitionTypes: []models.ModelType{models.ModelTypeJob}},
+		{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}", PartitionTypes: []models.ModelType{models.ModelTypeJob}},
+		{RouteKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions/{Version}", PartitionTypes: []models.ModelType{models.ModelTypeJob}},
+	}
+
+	tests := []struct {
+		name                   string
+		routeKey               string
+		modelIdentifiers       models.ModelIdentifiers
+		expectedKind           error
+		expectedAllowedMethods []string
+	}{
+		{name: "partition", routeKey: "GET /{PartitionType}/{PartitionId}/{SortType}", modelIdentifiers: models.ModelIdentifiers{PartitionType: models.ModelTypePerson, PartitionId: "p1"}},
+		{name: "sort ID", routeKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}", modelIdentifiers: models.ModelIdentifiers{PartitionType: models.ModelTypeJob, PartitionId: "j1", SortId: "l1"}},
+		{name: "version", routeKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions/{Version}", modelIdentifiers: models.ModelIdentifiers{PartitionType: models.ModelTypeJob, PartitionId: "j1", SortId: "l1", Version: 1}},
+		{name: "method not allowed", routeKey: "PUT /{PartitionType}/{PartitionId}/{SortType}/{SortId}", expectedKind: models.ErrMethodNotAllowed, expectedAllowedMethods: []string{"DELETE", "GET"}},
+		{name: "unknown route", routeKey: "GET /{SortType}", expectedKind: models.ErrNotFound},
+		{name: "invalid partition type", routeKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}", modelIdentifiers: models.ModelIdentifiers{PartitionType: models.ModelTypePerson, PartitionId: "p1", SortId: "l1"}, expectedKind: models.ErrValidation},
+		{name: "empty partition ID", routeKey: "GET /{PartitionType}/{PartitionId}/{SortType}", modelIdentifiers: models.ModelIdentifiers{PartitionType: models.ModelTypeJob}, expectedKind: models.ErrValidation},
+		{name: "empty sort ID", routeKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}", modelIdentifiers: models.ModelIdentifiers{PartitionType: models.ModelTypeJob, PartitionId: "j1"}, expectedKind: models.ErrValidation},
+		{name: "invalid version", routeKey: "GET /{PartitionType}/{PartitionId}/{SortType}/{SortId}/versions/{Version}", modelIdentifiers: models.ModelIdentifiers{PartitionType: models.ModelTypeJob, PartitionId: "j1", SortId: "l1"}, expectedKind: models.ErrValidation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			route, err := FindRoute(test.routeKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = validateModelRoute(modelRoutes, route, &test.modelIdentifiers)
+			if test.expectedKind == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, test.expectedKind) {
+				t.Fatalf("expected %v, got %v", test.expectedKind, err)
+			}
+
+			var modelError *models.ModelError
+			if errors.As(err, &modelError) && fmt.Sprint(modelError.AllowedMethods) != fmt.Sprint(test.expectedAllowedMethods) {
+				t.Fatalf("expected allowed methods %v, got %v", test.expectedAllowedMethods, modelError.AllowedMethods)
+			}
+		})
+	}
+}
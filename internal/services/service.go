@@ -2,27 +2,130 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"reflect"
+	"strings"
 
 	"j-and-a/internal/models"
 	"j-and-a/internal/repositories"
 )
 
-func New(repository *repositories.Repository, modelIdentifiers *models.ModelIdentifiers, routeKey string) (Service, error) {
-	switch modelIdentifiers.SortType {
-	case models.ModelTypeLog:
-		return NewLogService(repository, modelIdentifiers, routeKey)
-	case models.ModelTypePersonMetadata:
-		return NewPersonMetadataService(repository, modelIdentifiers, routeKey)
-	default:
-		return nil, errors.New("unsupported service")
+func New(table repositories.Table, modelIdentifiers *models.ModelIdentifiers, route Route) (Service, error) {
+	model, err := FindModel(modelIdentifiers.SortType)
+	if err != nil {
+		return nil, models.NewModelError(models.ErrNotFound, "unsupported service")
 	}
+	return model.NewService(table, modelIdentifiers, route)
 }
 
 type Service interface {
 	DeleteByPartitionIdAndSortId(ctx context.Context) error
-	GetByPartitionId(ctx context.Context) (interface{}, error)
+	GetByPartitionId(ctx context.Context, modelQuery *models.ModelQuery) (interface{}, error)
 	GetByPartitionIdAndSortId(ctx context.Context) (models.ModelData, error)
-	GetBySortType(ctx context.Context) ([]models.ModelData, error)
+	GetBySortType(ctx context.Context, modelQuery *models.ModelQuery) (*models.ModelPage, error)
+	GetDiffByPartitionIdAndSortId(ctx context.Context, fromVersion int, toVersion int) ([]models.ModelDiff, error)
+	GetSummaryByPartitionId(ctx context.Context, modelQuery *models.ModelQuery) (interface{}, error)
+	GetVersionByPartitionIdAndSortId(ctx context.Context) (models.ModelData, error)
+	GetVersionsByPartitionIdAndSortId(ctx context.Context) ([]models.ModelData, error)
 	PutByPartitionIdAndSortId(ctx context.Context, requestBody string) error
+	RestoreByPartitionIdAndSortId(ctx context.Context) error
+	RevertByPartitionIdAndSortId(ctx context.Context) error
+}
+
+type modelServiceAdapter[D models.TypedModelData[D]] struct {
+	*ModelService[D]
+}
+
+func (a *modelServiceAdapter[D]) GetByPartitionId(ctx context.Context, modelQuery *models.ModelQuery) (interface{}, error) {
+	if a.Model.Singleton {
+		data, err := a.ModelService.GetSingletonByPartitionId(ctx, modelQuery)
+		if err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	modelPage, err := a.ModelService.GetByPartitionId(ctx, modelQuery)
+	if err != nil {
+		return nil, err
+	}
+	return modelPage.ModelPage(), nil
+}
+
+func (a *modelServiceAdapter[D]) GetByPartitionIdAndSortId(ctx context.Context) (models.ModelData, error) {
+	data, err := a.ModelService.GetByPartitionIdAndSortId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *modelServiceAdapter[D]) GetBySortType(ctx context.Context, modelQuery *models.ModelQuery) (*models.ModelPage, error) {
+	modelPage, err := a.ModelService.GetBySortType(ctx, modelQuery)
+	if err != nil {
+		return nil, err
+	}
+	return modelPage.ModelPage(), nil
+}
+
+func (a *modelServiceAdapter[D]) GetVersionByPartitionIdAndSortId(ctx context.Context) (models.ModelData, error) {
+	data, err := a.ModelService.GetVersionByPartitionIdAndSortId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *modelServiceAdapter[D]) GetVersionsByPartitionIdAndSortId(ctx context.Context) ([]models.ModelData, error) {
+	datas, err := a.ModelService.GetVersionsByPartitionIdAndSortId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.ModelDatas(datas), nil
+}
+
+func decodeModelPayload(requestBody string, modelPayload models.ModelPayload) error {
+	decoder := json.NewDecoder(strings.NewReader(requestBody))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(modelPayload)
+	if err != nil {
+		var unmarshalTypeError *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &unmarshalTypeError):
+			return &models.ModelError{
+				Kind:        models.ErrValidation,
+				Message:     "invalid request body",
+				FieldErrors: []models.FieldError{{Field: unmarshalTypeError.Field, Message: "must be a " + jsonTypeName(unmarshalTypeError.Type)}},
+			}
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			return &models.ModelError{
+				Kind:        models.ErrValidation,
+				Message:     "invalid request body",
+				FieldErrors: []models.FieldError{{Field: strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`), Message: "is not allowed"}},
+			}
+		default:
+			return models.NewModelError(models.ErrValidation, "invalid request body")
+		}
+	}
+
+	if decoder.More() {
+		return models.NewModelError(models.ErrValidation, "invalid request body")
+	}
+
+	return modelPayload.Validate()
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "number"
+	case reflect.String:
+		return "string"
+	default:
+		return t.String()
+	}
 }
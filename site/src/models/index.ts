@@ -1,6 +1,7 @@
 import {
     columns as personMetadataColumns,
     getInitialValues as getPersonMetadataInitialValues,
+    getPayload as getPersonMetadataPayload,
     schema as personMetadataSchema,
     type Type as PersonMetadata,
 } from "@/models/personMetadata"
@@ -27,6 +28,7 @@ export const definitions = {
         partitionIdKey: "personId",
         sortType: "PersonMetadata",
         getInitialValues: getPersonMetadataInitialValues,
+        getPayload: getPersonMetadataPayload,
     },
 } satisfies Record<
     string,
@@ -39,5 +41,6 @@ export const definitions = {
         sortType: ModelType
         sortIdKey?: string
         getInitialValues: () => ModelTypes[ModelType]
+        getPayload: (values: ModelTypes[ModelType]) => Partial<ModelTypes[ModelType]>
     }
 >
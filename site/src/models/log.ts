@@ -15,6 +15,9 @@ import { z } from "zod"
 
 export const logSchema = z.object({
     personId: z.string().uuid(),
+    workDate: z.string().date(),
+    startTime: z.string().optional(),
+    endTime: z.string().optional(),
     hours: z.number().min(0),
     jobId: z.string().uuid(),
     logId: z.string().uuid(),
@@ -37,6 +40,12 @@ export const logColumns: ColumnDef<Log>[] = [
         header: ({ column }) => h(DataTableColumnHeader, { column: column, title: changeCase.capitalCase(column.id) }),
         cell: ({ row }) => h(DataTableStrikableCell, { row: row }, () => row.getValue("personId")),
     },
+    {
+        accessorKey: "workDate",
+        id: "workDate",
+        header: ({ column }) => h(DataTableColumnHeader, { column: column, title: changeCase.capitalCase(column.id) }),
+        cell: ({ row }) => h(DataTableStrikableCell, { row: row }, () => row.getValue("workDate")),
+    },
     {
         accessorKey: "hours",
         id: "hours",
@@ -15,6 +15,7 @@ export const schema = z.object({
     familyName: z.string().trim().min(1, "Required").default(""),
     personId: z.string().uuid().default(uuidv7),
 })
+const payloadSchema = schema.pick({ givenName: true, familyName: true })
 const mergedSchema = schema.merge(coreSchema)
 export type Type = z.infer<typeof mergedSchema>
 
@@ -26,6 +27,10 @@ export function getInitialValues() {
     }
 }
 
+export function getPayload(values: Type) {
+    return payloadSchema.parse(values)
+}
+
 export const columns: ColumnDef<Type>[] = [
     {
         id: "select",